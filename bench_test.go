@@ -0,0 +1,126 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+// The examples of RFC 5545, each expanded to at most 100 occurrences.
+//
+// To compare with the engine before occurrences were generated from the
+// BYxxx parts, run the benchmarks on both trees and compare with benchstat:
+//
+//	git worktree add /tmp/before 87f266b
+//	cp bench_test.go /tmp/before/
+//	(cd /tmp/before && go test -run xxx -bench 'RFC|Monthly|Step' -count 10) > before.txt
+//	go test -run xxx -bench 'RFC|Monthly|Step' -count 10 > after.txt
+//	benchstat before.txt after.txt
+//
+// Benchmark_Yearly is left out, that engine doesn't finish it.
+var benchmarkRules = []string{
+	"RRULE:FREQ=DAILY;COUNT=10",
+	"RRULE:FREQ=DAILY;UNTIL=19971224T000000Z",
+	"RRULE:FREQ=DAILY;INTERVAL=2",
+	"RRULE:FREQ=DAILY;INTERVAL=10;COUNT=5",
+	"RRULE:FREQ=YEARLY;UNTIL=20000131T140000Z;BYMONTH=1;BYDAY=SU,MO,TU,WE,TH,FR,SA",
+	"RRULE:FREQ=DAILY;UNTIL=20000131T140000Z;BYMONTH=1",
+	"RRULE:FREQ=WEEKLY;COUNT=10",
+	"RRULE:FREQ=WEEKLY;UNTIL=19971224T000000Z",
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;WKST=SU",
+	"RRULE:FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH",
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;WKST=SU;BYDAY=MO,WE,FR",
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=8;WKST=SU;BYDAY=TU,TH",
+	"RRULE:FREQ=MONTHLY;COUNT=10;BYDAY=1FR",
+	"RRULE:FREQ=MONTHLY;UNTIL=19971224T000000Z;BYDAY=1FR",
+	"RRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU",
+	"RRULE:FREQ=MONTHLY;COUNT=6;BYDAY=-2MO",
+	"RRULE:FREQ=MONTHLY;BYMONTHDAY=-3",
+	"RRULE:FREQ=MONTHLY;COUNT=10;BYMONTHDAY=2,15",
+	"RRULE:FREQ=MONTHLY;COUNT=10;BYMONTHDAY=1,-1",
+	"RRULE:FREQ=MONTHLY;INTERVAL=18;COUNT=10;BYMONTHDAY=10,11,12,13,14,15",
+	"RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=TU",
+	"RRULE:FREQ=YEARLY;COUNT=10;BYMONTH=6,7",
+	"RRULE:FREQ=YEARLY;INTERVAL=2;COUNT=10;BYMONTH=1,2,3",
+	"RRULE:FREQ=YEARLY;INTERVAL=3;COUNT=10;BYYEARDAY=1,100,200",
+	"RRULE:FREQ=YEARLY;BYDAY=20MO",
+	"RRULE:FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO",
+	"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=TH",
+	"RRULE:FREQ=YEARLY;BYDAY=TH;BYMONTH=6,7,8",
+	"RRULE:FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+	"RRULE:FREQ=MONTHLY;BYDAY=SA;BYMONTHDAY=7,8,9,10,11,12,13",
+	"RRULE:FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8",
+	"RRULE:FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3",
+	"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2",
+	"RRULE:FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T170000Z",
+	"RRULE:FREQ=MINUTELY;INTERVAL=15;COUNT=6",
+	"RRULE:FREQ=MINUTELY;INTERVAL=90;COUNT=4",
+	"RRULE:FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40",
+	"RRULE:FREQ=MINUTELY;INTERVAL=20;BYHOUR=9,10,11,12,13,14,15,16",
+	"RRULE:FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5",
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO",
+}
+
+func benchmarkRuleSet(b *testing.B) []*RecurringRule {
+	var rules []*RecurringRule
+	for _, value := range benchmarkRules {
+		rule, err := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + value)
+		if err != nil {
+			b.Fatal(value, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func Benchmark_RFCExamples(b *testing.B) {
+	rules := benchmarkRuleSet(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var event time.Time
+	for i := 0; i < b.N; i++ {
+		for _, rule := range rules {
+			iter := rule.Iterator().Limit(100)
+			for iter.Step(&event) {
+			}
+		}
+	}
+}
+
+func Benchmark_Step(b *testing.B) {
+	rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;BYMONTH=1,7;BYDAY=1MO,-1FR")
+	iter := rule.Iterator()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var event time.Time
+	for i := 0; i < b.N; i++ {
+		iter.Step(&event)
+	}
+}
+
+func Benchmark_Yearly(b *testing.B) {
+	rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;COUNT=100;BYMONTH=11;BYDAY=4TH")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var event time.Time
+	for i := 0; i < b.N; i++ {
+		iter := rule.Iterator()
+		for iter.Step(&event) {
+		}
+	}
+}
+
+func Benchmark_Monthly(b *testing.B) {
+	rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;COUNT=100;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var event time.Time
+	for i := 0; i < b.N; i++ {
+		iter := rule.Iterator()
+		for iter.Step(&event) {
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Days of the week for Builder.On, use Nth for an offset such as the last
+// Friday, FR.Nth(-1).
+var (
+	MO = ForDay{Weekday: time.Monday}
+	TU = ForDay{Weekday: time.Tuesday}
+	WE = ForDay{Weekday: time.Wednesday}
+	TH = ForDay{Weekday: time.Thursday}
+	FR = ForDay{Weekday: time.Friday}
+	SA = ForDay{Weekday: time.Saturday}
+	SU = ForDay{Weekday: time.Sunday}
+)
+
+// Nth returns the nth occurrence of the day within the month or year,
+// negative values count from the end.
+func (fd ForDay) Nth(n int) ForDay {
+	return ForDay{Weekday: fd.Weekday, Offset: n}
+}
+
+// ValidationError lists every problem Build found in a rule, rather than
+// stopping at the first.
+type ValidationError struct {
+	Problems []string
+}
+
+func (ve *ValidationError) Error() string {
+	return fmt.Sprintf("invalid rule: %s", strings.Join(ve.Problems, "; "))
+}
+
+// Add records a problem, formatted with fmt.Sprintf.
+func (ve *ValidationError) Add(format string, args ...interface{}) {
+	ve.Problems = append(ve.Problems, fmt.Sprintf(format, args...))
+}
+
+// Err returns nil when no problems were recorded.
+func (ve *ValidationError) Err() error {
+	if len(ve.Problems) == 0 {
+		return nil
+	}
+	return ve
+}
+
+// Builder builds a RecurringRule with the same defaults as Parse.
+//
+//	rule, err := rrule.Weekly().Every(2).On(rrule.MO, rrule.WE).At(9, 0).From(t).Count(10).Build()
+//
+// Nothing is checked until Build, which returns every problem at once.
+type Builder struct {
+	rule     RecurringRule
+	hasStart bool
+	times    [][2]int
+	interval int
+}
+
+func NewBuilder(freq FrequencyValue) *Builder {
+	return &Builder{
+		rule: RecurringRule{
+			Frequency:     freq,
+			Interval:      1,
+			WorkWeekStart: time.Monday,
+		},
+		interval: 1,
+	}
+}
+
+func Yearly() *Builder   { return NewBuilder(YEARLY) }
+func Monthly() *Builder  { return NewBuilder(MONTHLY) }
+func Weekly() *Builder   { return NewBuilder(WEEKLY) }
+func Daily() *Builder    { return NewBuilder(DAILY) }
+func Hourly() *Builder   { return NewBuilder(HOURLY) }
+func Minutely() *Builder { return NewBuilder(MINUTELY) }
+func Secondly() *Builder { return NewBuilder(SECONDLY) }
+
+// Every sets INTERVAL.
+func (b *Builder) Every(interval int) *Builder {
+	b.interval = interval
+	return b
+}
+
+// From sets DtStart, it's required. The clock of start is used unless At
+// is given.
+func (b *Builder) From(start time.Time) *Builder {
+	b.rule.DtStart = start
+	b.hasStart = true
+	return b
+}
+
+// At sets the time of day of DtStart. Calling it more than once sets
+// BYHOUR and BYMINUTE to every hour and minute given instead, which is
+// every combination of them, so the times have to be all of those
+// combinations: 9:00, 9:30, 17:00 and 17:30 are, 9:00 and 17:30 aren't.
+func (b *Builder) At(hour, minute int) *Builder {
+	b.times = append(b.times, [2]int{hour, minute})
+	return b
+}
+
+func (b *Builder) On(days ...ForDay) *Builder {
+	b.rule.ByDay = append(b.rule.ByDay, days...)
+	return b
+}
+
+func (b *Builder) OnMonthDays(days ...int) *Builder {
+	b.rule.ByMonthDay = append(b.rule.ByMonthDay, intsToInt16(days)...)
+	return b
+}
+
+func (b *Builder) OnYearDays(days ...int) *Builder {
+	b.rule.ByYearDay = append(b.rule.ByYearDay, intsToInt16(days)...)
+	return b
+}
+
+func (b *Builder) InWeeks(weeks ...int) *Builder {
+	b.rule.ByWeekNo = append(b.rule.ByWeekNo, intsToInt16(weeks)...)
+	return b
+}
+
+func (b *Builder) InMonths(months ...time.Month) *Builder {
+	for _, m := range months {
+		b.rule.ByMonth = append(b.rule.ByMonth, int16(m))
+	}
+	return b
+}
+
+func (b *Builder) AtHours(hours ...int) *Builder {
+	b.rule.ByHour = append(b.rule.ByHour, intsToInt16(hours)...)
+	return b
+}
+
+func (b *Builder) AtMinutes(minutes ...int) *Builder {
+	b.rule.ByMinute = append(b.rule.ByMinute, intsToInt16(minutes)...)
+	return b
+}
+
+func (b *Builder) AtSeconds(seconds ...int) *Builder {
+	b.rule.BySecond = append(b.rule.BySecond, intsToInt16(seconds)...)
+	return b
+}
+
+// SetPos sets BYSETPOS, the positions within each period to keep.
+func (b *Builder) SetPos(positions ...int) *Builder {
+	b.rule.BySetPos = append(b.rule.BySetPos, intsToInt16(positions)...)
+	return b
+}
+
+func (b *Builder) WeekStart(day time.Weekday) *Builder {
+	b.rule.WorkWeekStart = day
+	return b
+}
+
+func (b *Builder) Count(count int) *Builder {
+	b.rule.Count = count
+	return b
+}
+
+func (b *Builder) Until(until time.Time) *Builder {
+	b.rule.Until = until
+	return b
+}
+
+// Except adds EXDATEs.
+func (b *Builder) Except(dates ...time.Time) *Builder {
+	b.rule.ExceptionsToRule = append(b.rule.ExceptionsToRule, dates...)
+	return b
+}
+
+// Build validates the rule and returns a copy of it, the builder can be
+// changed and built again afterwards.
+func (b *Builder) Build() (*RecurringRule, error) {
+	rr := b.rule.Clone()
+	rr.Interval = b.interval
+
+	if len(b.times) == 1 {
+		start := rr.DtStart
+		rr.DtStart = time.Date(
+			start.Year(), start.Month(), start.Day(),
+			b.times[0][0], b.times[0][1], 0, 0, start.Location(),
+		)
+	}
+
+	var hours, minutes []int16
+	times := map[[2]int]bool{}
+	for _, t := range b.times {
+		hours = appendUniqueInt16(hours, int16(t[0]))
+		minutes = appendUniqueInt16(minutes, int16(t[1]))
+		times[t] = true
+	}
+
+	ve := &ValidationError{}
+
+	if len(b.times) > 1 {
+		if len(times) != len(hours)*len(minutes) {
+			ve.Add("times can't be expressed as BYHOUR and BYMINUTE")
+		}
+		for _, h := range hours {
+			rr.ByHour = appendUniqueInt16(rr.ByHour, h)
+		}
+		for _, m := range minutes {
+			rr.ByMinute = appendUniqueInt16(rr.ByMinute, m)
+		}
+	}
+
+	if !b.hasStart {
+		ve.Add("a start is required, use From")
+	}
+	for _, t := range b.times {
+		if t[0] < 0 || t[0] > 23 || t[1] < 0 || t[1] > 59 {
+			ve.Add("invalid time %02d:%02d", t[0], t[1])
+		}
+	}
+
+	rr.validate(ve)
+
+	if err := ve.Err(); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// Validate makes the checks of Build on a rule from anywhere else, it
+// returns a ValidationError with every problem found.
+func (rr *RecurringRule) Validate() error {
+	ve := &ValidationError{}
+	rr.validate(ve)
+	return ve.Err()
+}
+
+// validate checks the ranges of every part and the combinations RFC 5545
+// doesn't allow.
+func (rr *RecurringRule) validate(ve *ValidationError) {
+	if rr.Frequency > SECONDLY {
+		ve.Add("unknown frequency %d", rr.Frequency)
+		return
+	}
+
+	if rr.Interval < 1 {
+		ve.Add("INTERVAL must be at least 1")
+	}
+	if rr.Count < 0 {
+		ve.Add("COUNT can't be negative")
+	}
+	if rr.Count > 0 && !rr.Until.Equal(EmptyTime) {
+		ve.Add("COUNT and UNTIL can't both be set")
+	}
+	if !rr.Until.Equal(EmptyTime) && rr.Until.Before(rr.DtStart) {
+		ve.Add("UNTIL is before the start")
+	}
+
+	ranges := []struct {
+		name     string
+		values   []int16
+		min, max int16
+		zero     bool
+	}{
+		{"BYSECOND", rr.BySecond, 0, 60, true},
+		{"BYMINUTE", rr.ByMinute, 0, 59, true},
+		{"BYHOUR", rr.ByHour, 0, 23, true},
+		{"BYMONTHDAY", rr.ByMonthDay, -31, 31, false},
+		{"BYYEARDAY", rr.ByYearDay, -366, 366, false},
+		{"BYWEEKNO", rr.ByWeekNo, -53, 53, false},
+		{"BYMONTH", rr.ByMonth, 1, 12, false},
+		{"BYSETPOS", rr.BySetPos, -366, 366, false},
+	}
+
+	for _, r := range ranges {
+		for _, v := range r.values {
+			if v < r.min || v > r.max || v == 0 && !r.zero {
+				ve.Add("%s value %d is out of range", r.name, v)
+			}
+		}
+	}
+
+	for _, fd := range rr.ByDay {
+		if fd.Weekday < time.Sunday || fd.Weekday > time.Saturday {
+			ve.Add("BYDAY has an invalid weekday %d", fd.Weekday)
+		}
+		if fd.Offset < -53 || fd.Offset > 53 {
+			ve.Add("BYDAY offset %d is out of range", fd.Offset)
+		}
+		if fd.Offset != 0 && rr.Frequency != MONTHLY && rr.Frequency != YEARLY {
+			ve.Add("BYDAY offsets are only allowed in MONTHLY and YEARLY rules")
+			break
+		}
+		if fd.Offset != 0 && rr.Frequency == YEARLY && len(rr.ByWeekNo) > 0 {
+			ve.Add("BYDAY offsets can't be combined with BYWEEKNO")
+			break
+		}
+	}
+
+	if len(rr.ByMonthDay) > 0 && rr.Frequency == WEEKLY {
+		ve.Add("BYMONTHDAY is not allowed in WEEKLY rules")
+	}
+	if len(rr.ByYearDay) > 0 && (rr.Frequency == MONTHLY || rr.Frequency == WEEKLY || rr.Frequency == DAILY) {
+		ve.Add("BYYEARDAY is not allowed in %s rules", rr.Frequency)
+	}
+	if len(rr.ByWeekNo) > 0 && rr.Frequency != YEARLY {
+		ve.Add("BYWEEKNO is only allowed in YEARLY rules")
+	}
+
+	others := *rr
+	others.BySetPos = nil
+	if len(rr.BySetPos) > 0 && !others.hasByParts() {
+		ve.Add("BYSETPOS needs another BY part to select from")
+	}
+}
@@ -0,0 +1,108 @@
+package rrule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Builder(t *testing.T) {
+	start := time.Date(1997, time.September, 2, 0, 0, 0, 0, time.UTC)
+
+	var cases = []struct {
+		builder  *Builder
+		expected string
+	}{
+		{
+			Weekly().Every(2).On(MO, WE).At(9, 0).From(start).Count(10),
+			"DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10",
+		},
+		{
+			Monthly().On(FR.Nth(-1)).From(start).Until(time.Date(1997, time.December, 24, 0, 0, 0, 0, time.UTC)),
+			"DTSTART;TZID=UTC:19970902T000000Z\nRRULE:FREQ=MONTHLY;BYDAY=-1FR;UNTIL=19971224T000000Z",
+		},
+		{
+			Daily().At(9, 30).At(17, 30).From(start),
+			"DTSTART;TZID=UTC:19970902T000000Z\nRRULE:FREQ=DAILY;BYHOUR=9,17;BYMINUTE=30",
+		},
+		{
+			Daily().At(9, 0).At(17, 30).At(9, 30).At(17, 0).From(start),
+			"DTSTART;TZID=UTC:19970902T000000Z\nRRULE:FREQ=DAILY;BYHOUR=9,17;BYMINUTE=0,30",
+		},
+		{
+			Yearly().InMonths(time.January, time.March).On(SU.Nth(1)).WeekStart(time.Sunday).From(start),
+			"DTSTART;TZID=UTC:19970902T000000Z\nRRULE:FREQ=YEARLY;BYMONTH=1,3;BYDAY=1SU;WKST=SU",
+		},
+		{
+			Monthly().On(MO, TU, WE, TH, FR).SetPos(-1).From(start).Except(start),
+			"DTSTART;TZID=UTC:19970902T000000Z\nEXDATE;TZID=UTC:19970902T000000Z\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		},
+	}
+
+	for _, c := range cases {
+		rule, err := c.builder.Build()
+		if err != nil {
+			t.Log("Failed to build", c.expected, err)
+			t.Fail()
+			continue
+		}
+
+		expected, _ := Parse(c.expected)
+		if rule.String() != expected.String() {
+			t.Log("Unexpected rule", c.expected)
+			t.Log(rule)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Builder_Invalid(t *testing.T) {
+	start := time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+	var cases = []struct {
+		builder  *Builder
+		problems int
+	}{
+		{Weekly(), 1},
+		{Weekly().Every(0).From(start), 1},
+		{Daily().From(start).Count(3).Until(start.AddDate(0, 1, 0)), 1},
+		{Daily().From(start).Until(start.AddDate(0, -1, 0)), 1},
+		{Weekly().On(MO.Nth(2)).From(start), 1},
+		{Weekly().OnMonthDays(1).From(start), 1},
+		{Monthly().OnMonthDays(0, 32).From(start), 2},
+		{Monthly().InWeeks(3).From(start), 1},
+		{Daily().At(25, 0).From(start), 1},
+		{Daily().At(9, 0).At(17, 30).From(start), 1},
+		{Monthly().SetPos(1).From(start), 1},
+		{Yearly().InMonths(13).AtHours(24).From(start), 2},
+	}
+
+	for _, c := range cases {
+		rule, err := c.builder.Build()
+
+		ve, ok := err.(*ValidationError)
+		if !ok || rule != nil || len(ve.Problems) != c.problems {
+			t.Log("Unexpected result", rule, err)
+			t.Fail()
+		}
+	}
+
+	_, err := Weekly().Every(0).Build()
+	if err == nil || !strings.Contains(err.Error(), "From") || !strings.Contains(err.Error(), "INTERVAL") {
+		t.Log("Expected every problem in the error", err)
+		t.Fail()
+	}
+}
+
+func Test_Builder_Reuse(t *testing.T) {
+	start := time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+	builder := Weekly().On(MO).From(start)
+	first, _ := builder.Build()
+	second, _ := builder.On(FR).Build()
+
+	if len(first.ByDay) != 1 || len(second.ByDay) != 2 {
+		t.Log("Built rules share state", first, second)
+		t.Fail()
+	}
+}
@@ -0,0 +1,97 @@
+package rrule
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Clone(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=UTC:19970902T090000Z\n" +
+			"EXDATE;TZID=UTC:19970903T090000Z\n" +
+			"RRULE:FREQ=MONTHLY;BYDAY=MO,FR;BYMONTHDAY=1,2;BYSETPOS=-1;BYHOUR=9",
+	)
+
+	clone := rule.Clone()
+	if !clone.Equal(rule) {
+		t.Log("Clone doesn't match", clone)
+		t.Fail()
+	}
+
+	clone.ByDay[0].Weekday = time.Sunday
+	clone.ByMonthDay[0] = 5
+	clone.BySetPos[0] = 1
+	clone.ByHour[0] = 10
+	clone.ExceptionsToRule[0] = EmptyTime
+
+	if rule.ByDay[0].Weekday != time.Monday || rule.ByMonthDay[0] != 1 || rule.BySetPos[0] != -1 ||
+		rule.ByHour[0] != 9 || rule.ExceptionsToRule[0].Equal(EmptyTime) {
+		t.Log("Clone shares slices with the original", rule)
+		t.Fail()
+	}
+}
+
+func Test_With_Modifiers(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=DAILY;UNTIL=19971224T000000Z")
+	original := rule.Clone()
+
+	start := time.Date(1998, time.January, 1, 9, 0, 0, 0, time.UTC)
+	exceptions := []time.Time{start.AddDate(0, 0, 1)}
+
+	changed := rule.WithDtStart(start).WithUntil(EmptyTime).WithExceptions(exceptions...)
+
+	if !rule.Equal(original) {
+		t.Log("With modifiers changed the original", rule)
+		t.Fail()
+	}
+
+	if !changed.DtStart.Equal(start) || !changed.Until.Equal(EmptyTime) || len(changed.ExceptionsToRule) != 1 {
+		t.Log("Unexpected rule", changed)
+		t.Fail()
+	}
+
+	exceptions[0] = EmptyTime
+	if changed.ExceptionsToRule[0].Equal(EmptyTime) {
+		t.Log("WithExceptions shares the slice it was given")
+		t.Fail()
+	}
+}
+
+func Test_Iterator_Snapshot(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=10")
+
+	iter := rule.Iterator()
+	rule.DtStart = rule.DtStart.AddDate(1, 0, 0)
+	rule.Count = 2
+
+	var event time.Time
+	var count int
+	for iter.Step(&event) {
+		if count == 0 && event.Year() != 1997 {
+			t.Log("Iterator saw a change made after it was created", event)
+			t.Fail()
+		}
+		count += 1
+	}
+
+	if count != 10 {
+		t.Log("Unexpected number of occurrences", count)
+		t.Fail()
+	}
+
+	// A shared rule can be iterated and derived from at the same time.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+
+			var event time.Time
+			iter := rule.WithDtStart(rule.DtStart.AddDate(0, 0, offset)).Iterator()
+			for iter.Step(&event) {
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,314 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+// The examples of RFC 5545 section 3.8.5.3 not in basic_test.go, and cases
+// for each row of the BYxxx table in section 3.3.10. Expected times are in
+// the location of DTSTART unless they end with a Z.
+var conformanceCases = []struct {
+	name     string
+	rule     string
+	expected []string
+	// complete is set when the rule has no more occurrences.
+	complete bool
+}{
+	{
+		"RFC weekly until December 24, 1997",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=WEEKLY;UNTIL=19971224T000000Z",
+		[]string{
+			"19970902T090000", "19970909T090000", "19970916T090000", "19970923T090000",
+			"19970930T090000", "19971007T090000", "19971014T090000", "19971021T090000",
+			"19971028T090000", "19971104T090000", "19971111T090000", "19971118T090000",
+			"19971125T090000", "19971202T090000", "19971209T090000", "19971216T090000",
+			"19971223T090000",
+		},
+		true,
+	},
+	{
+		"RFC every other week forever",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;WKST=SU",
+		[]string{
+			"19970902T090000", "19970916T090000", "19970930T090000", "19971014T090000",
+			"19971028T090000", "19971111T090000", "19971125T090000", "19971209T090000",
+			"19971223T090000", "19980106T090000", "19980120T090000", "19980203T090000",
+			"19980217T090000",
+		},
+		false,
+	},
+	{
+		"RFC every other week on Tuesday and Thursday, for 8 occurrences",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=8;WKST=SU;BYDAY=TU,TH",
+		[]string{
+			"19970902T090000", "19970904T090000", "19970916T090000", "19970918T090000",
+			"19970930T090000", "19971002T090000", "19971014T090000", "19971016T090000",
+		},
+		true,
+	},
+	{
+		"RFC monthly on the first Friday for 10 occurrences",
+		"DTSTART;TZID=America/New_York:19970905T090000\nRRULE:FREQ=MONTHLY;COUNT=10;BYDAY=1FR",
+		[]string{
+			"19970905T090000", "19971003T090000", "19971107T090000", "19971205T090000",
+			"19980102T090000", "19980206T090000", "19980306T090000", "19980403T090000",
+			"19980501T090000", "19980605T090000",
+		},
+		true,
+	},
+	{
+		"BYDAY offset within BYMONTH",
+		"DTSTART;TZID=UTC:20241128T120000Z\nRRULE:FREQ=YEARLY;COUNT=5;BYMONTH=11;BYDAY=4TH",
+		[]string{"20241128T120000Z", "20251127T120000Z", "20261126T120000Z", "20271125T120000Z", "20281123T120000Z"},
+		true,
+	},
+	{
+		"BYDAY offset within each of several months",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=YEARLY;COUNT=6;BYMONTH=7,1;BYDAY=1MO",
+		[]string{
+			"20240101T090000Z", "20240701T090000Z", "20250106T090000Z",
+			"20250707T090000Z", "20260105T090000Z", "20260706T090000Z",
+		},
+		true,
+	},
+	{
+		"Negative BYDAY offset within BYMONTH",
+		"DTSTART;TZID=Europe/Berlin:20240101T030000\nRRULE:FREQ=YEARLY;COUNT=4;BYMONTH=3,10;BYDAY=-1SU",
+		[]string{"20240331T030000", "20241027T030000", "20250330T030000", "20251026T030000"},
+		true,
+	},
+	{
+		"BYDAY offset within the year",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=YEARLY;COUNT=3;BYDAY=-1FR",
+		[]string{"20241227T090000Z", "20251226T090000Z", "20261225T090000Z"},
+		true,
+	},
+	{
+		"First and last weekday of the year",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=YEARLY;COUNT=4;BYDAY=-1MO,1MO",
+		[]string{"20240101T090000Z", "20241230T090000Z", "20250106T090000Z", "20251229T090000Z"},
+		true,
+	},
+	{
+		"BYWEEKNO with days in the year before",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;COUNT=3;BYWEEKNO=1;BYDAY=MO",
+		[]string{"19971229T090000", "19990104T090000", "20000103T090000"},
+		true,
+	},
+	{
+		"BYWEEKNO 53 only in years that have it",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;COUNT=3;BYWEEKNO=53;BYDAY=MO",
+		[]string{"19981228T090000", "20041227T090000", "20091228T090000"},
+		true,
+	},
+	{
+		"Negative BYWEEKNO",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;COUNT=3;BYWEEKNO=-1;BYDAY=SU",
+		[]string{"19971228T090000", "19990103T090000", "20000102T090000"},
+		true,
+	},
+	{
+		"BYWEEKNO with weeks starting on Sunday",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;COUNT=3;BYWEEKNO=1;BYDAY=SU;WKST=SU",
+		[]string{"19980104T090000", "19990103T090000", "20000102T090000"},
+		true,
+	},
+	{
+		"Negative BYWEEKNO with weeks starting on Sunday",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=YEARLY;COUNT=4;BYWEEKNO=-2;BYDAY=TH;WKST=SU",
+		[]string{"20241219T090000Z", "20251225T090000Z", "20261224T090000Z", "20271223T090000Z"},
+		true,
+	},
+	{
+		"BYYEARDAY across a leap year",
+		"DTSTART;TZID=UTC:20230101T090000Z\nRRULE:FREQ=YEARLY;COUNT=3;BYYEARDAY=60",
+		[]string{"20230301T090000Z", "20240229T090000Z", "20250301T090000Z"},
+		true,
+	},
+	{
+		"Negative BYYEARDAY",
+		"DTSTART;TZID=UTC:20230101T090000Z\nRRULE:FREQ=YEARLY;COUNT=3;BYYEARDAY=-1",
+		[]string{"20231231T090000Z", "20241231T090000Z", "20251231T090000Z"},
+		true,
+	},
+	{
+		"BYYEARDAY limited by BYDAY",
+		"DTSTART;TZID=UTC:20230101T090000Z\nRRULE:FREQ=YEARLY;UNTIL=20261231T000000Z;BYYEARDAY=100,200;BYDAY=MO",
+		[]string{"20230410T090000Z"},
+		true,
+	},
+	{
+		"Negative BYMONTHDAY within BYMONTH",
+		"DTSTART;TZID=UTC:20230101T090000Z\nRRULE:FREQ=YEARLY;COUNT=2;BYMONTH=2;BYMONTHDAY=-1",
+		[]string{"20230228T090000Z", "20240229T090000Z"},
+		true,
+	},
+	{
+		"Negative BYMONTHDAY limiting DAILY",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=3;BYMONTHDAY=-1",
+		[]string{"19970930T090000", "19971031T090000", "19971130T090000"},
+		true,
+	},
+	{
+		"Unsorted BYMONTH",
+		"DTSTART;TZID=UTC:20231101T090000Z\nRRULE:FREQ=YEARLY;COUNT=3;BYMONTH=12,1;BYMONTHDAY=1",
+		[]string{"20231201T090000Z", "20240101T090000Z", "20241201T090000Z"},
+		true,
+	},
+	{
+		"Yearly on a leap day",
+		"DTSTART;TZID=UTC:20200229T090000Z\nRRULE:FREQ=YEARLY;COUNT=3",
+		[]string{"20200229T090000Z", "20240229T090000Z", "20280229T090000Z"},
+		true,
+	},
+	{
+		"Yearly doesn't drift in leap years",
+		"DTSTART;TZID=UTC:20230301T090000Z\nRRULE:FREQ=YEARLY;COUNT=3",
+		[]string{"20230301T090000Z", "20240301T090000Z", "20250301T090000Z"},
+		true,
+	},
+	{
+		"Monthly on the 31st skips shorter months",
+		"DTSTART;TZID=UTC:20240131T090000Z\nRRULE:FREQ=MONTHLY;COUNT=4",
+		[]string{"20240131T090000Z", "20240331T090000Z", "20240531T090000Z", "20240731T090000Z"},
+		true,
+	},
+	{
+		"Monthly expanded by BYHOUR keeps the day of DTSTART",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;COUNT=4;BYHOUR=9,17",
+		[]string{"19970902T090000", "19970902T170000", "19971002T090000", "19971002T170000"},
+		true,
+	},
+	{
+		"Unsorted BYHOUR",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=4;BYHOUR=17,9,12",
+		[]string{"19970902T090000", "19970902T120000", "19970902T170000", "19970903T090000"},
+		true,
+	},
+	{
+		"Duplicate BYxxx values",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=TU,TU,WE;BYHOUR=9,9",
+		[]string{"19970902T090000", "19970903T090000", "19970909T090000"},
+		true,
+	},
+	{
+		"BYSETPOS counts times",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=3;BYHOUR=9,12,17;BYSETPOS=-1",
+		[]string{"19970902T170000", "19970903T170000", "19970904T170000"},
+		true,
+	},
+	{
+		"BYSETPOS across days and times",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;COUNT=3;BYMONTHDAY=13,17;BYHOUR=6,18;BYSETPOS=3,-3",
+		[]string{"19970913T180000", "19970917T060000", "19971013T180000"},
+		true,
+	},
+	{
+		"Yearly BYSETPOS across days and times",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=YEARLY;COUNT=3;BYMONTHDAY=15;BYHOUR=6,18;BYSETPOS=3,-3",
+		[]string{"19971115T180000", "19980215T060000", "19981115T180000"},
+		true,
+	},
+	{
+		"Last weekday of the month in the evening",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;COUNT=3;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,17;BYSETPOS=-1",
+		[]string{"19970930T170000", "19971031T170000", "19971128T170000"},
+		true,
+	},
+	{
+		"Hourly limited by BYDAY and BYHOUR",
+		"DTSTART;TZID=America/New_York:19970905T220000\nRRULE:FREQ=HOURLY;COUNT=4;INTERVAL=2;BYDAY=MO,FR;BYHOUR=0,2,22",
+		[]string{"19970905T220000", "19970908T000000", "19970908T020000", "19970908T220000"},
+		true,
+	},
+	{
+		"Secondly limited by every part",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=SECONDLY;COUNT=3;BYMINUTE=0;BYSECOND=0,30",
+		[]string{"20240101T090000Z", "20240101T090030Z", "20240101T100000Z"},
+		true,
+	},
+	{
+		"Daily through a skipped hour",
+		"DTSTART;TZID=America/New_York:20240309T023000\nRRULE:FREQ=DAILY;COUNT=3",
+		[]string{"20240309T073000Z", "20240310T073000Z", "20240311T063000Z"},
+		true,
+	},
+	{
+		"Daily through a skipped half hour",
+		"DTSTART;TZID=Australia/Lord_Howe:20241005T021500\nRRULE:FREQ=DAILY;COUNT=2",
+		[]string{"20241004T154500Z", "20241005T154500Z"},
+		true,
+	},
+	{
+		"Hourly through a repeated hour",
+		"DTSTART;TZID=America/New_York:20241103T000000\nRRULE:FREQ=HOURLY;COUNT=4",
+		[]string{"20241103T040000Z", "20241103T050000Z", "20241103T060000Z", "20241103T070000Z"},
+		true,
+	},
+	{
+		"Weekly with a week start crossing the year",
+		"DTSTART;TZID=UTC:20241229T090000Z\nRRULE:FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=SU,WE;WKST=TH",
+		[]string{"20241229T090000Z", "20250101T090000Z", "20250112T090000Z", "20250115T090000Z"},
+		true,
+	},
+	{
+		"Impossible dates are skipped",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=YEARLY;UNTIL=20300101T000000Z;BYMONTH=2;BYMONTHDAY=30",
+		nil,
+		true,
+	},
+}
+
+func Test_Conformance(t *testing.T) {
+	for _, c := range conformanceCases {
+		rule, err := Parse(c.rule)
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+
+		iter := rule.Iterator().HardLimit(100000)
+		var event time.Time
+
+		for _, value := range c.expected {
+			expected, _ := ParseDateTime(value, rule.DtStart.Location())
+			if !iter.Step(&event) {
+				t.Log(c.name, "ended before", value)
+				t.Fail()
+				break
+			}
+			if !event.Equal(expected) {
+				t.Log(c.name, "expected", expected, "got", event)
+				t.Fail()
+				break
+			}
+		}
+
+		if c.complete && iter.Step(&event) {
+			t.Log(c.name, "has more occurrences", event)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Conformance_Sorted(t *testing.T) {
+	// Every frequency with lists out of order returns sorted, unique
+	// occurrences.
+	for _, freq := range []string{"YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY"} {
+		rule, _ := Parse(
+			"DTSTART;TZID=America/New_York:19970902T090000\n" +
+				"RRULE:FREQ=" + freq + ";BYMONTH=11,3,3;BYDAY=SU,MO,SU;BYHOUR=1,23,2,1;BYMINUTE=30,0",
+		)
+
+		var last, event time.Time
+		iter := rule.Iterator().Limit(100).HardLimit(1000000)
+		for iter.Step(&event) {
+			if !event.After(last) {
+				t.Log(freq, "out of order", last, event)
+				t.Fail()
+				break
+			}
+			last = event
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConversionError is returned by the converters between RecurringRule and
+// other recurrence formats. It lists every part of the input that could not
+// be represented in the target format, rather than stopping at the first.
+type ConversionError struct {
+	Format   string
+	Problems []string
+}
+
+func (ce *ConversionError) Error() string {
+	return fmt.Sprintf(
+		"%s conversion failed: %s",
+		ce.Format,
+		strings.Join(ce.Problems, "; "),
+	)
+}
+
+// Add records a problem, formatted with fmt.Sprintf.
+func (ce *ConversionError) Add(format string, args ...interface{}) {
+	ce.Problems = append(ce.Problems, fmt.Sprintf(format, args...))
+}
+
+// Err returns nil when no problems were recorded, so converters can
+// collect everything and return ce.Err() at the end.
+func (ce *ConversionError) Err() error {
+	if len(ce.Problems) == 0 {
+		return nil
+	}
+	return ce
+}
+
+// shortestMonth is the fewest days the month can have, or any month
+// when month is 0.
+func shortestMonth(month int) int {
+	if month < 1 || month > 12 || month == 2 {
+		return 28
+	}
+	return time.Date(2001, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// clampedMonthDayInto sets an absolute day of the month. Outlook moves
+// days past the end of a short month to its last day, "the last of
+// shortest..day" is the same thing in RRULE terms.
+func clampedMonthDayInto(rr *RecurringRule, day int, month int, ce *ConversionError) {
+	if day < 1 || day > 31 {
+		ce.Add("invalid day of month %d", day)
+		return
+	}
+
+	shortest := shortestMonth(month)
+	if day <= shortest {
+		rr.ByMonthDay = []int16{int16(day)}
+		return
+	}
+
+	for d := shortest; d <= day; d += 1 {
+		rr.ByMonthDay = append(rr.ByMonthDay, int16(d))
+	}
+	rr.BySetPos = []int16{-1}
+}
+
+// relativeDaysInto sets the nth day of the period matching any of the days
+// of the week. A single day uses an offset, several days need BYSETPOS.
+func relativeDaysInto(rr *RecurringRule, days []ForDay, offset int) {
+	if len(days) == 1 {
+		rr.ByDay = []ForDay{{Weekday: days[0].Weekday, Offset: offset}}
+		return
+	}
+
+	rr.ByDay = days
+	rr.BySetPos = []int16{int16(offset)}
+}
+
+// relativeDays is the reverse of relativeDaysInto, it returns the days of
+// the week without offsets and the position among them.
+func (rr *RecurringRule) relativeDays(ce *ConversionError) ([]ForDay, int) {
+	var days []ForDay
+
+	offset := rr.ByDay[0].Offset
+	for _, fd := range rr.ByDay {
+		if fd.Offset != offset {
+			ce.Add("BYDAY can't mix offsets")
+		}
+		days = append(days, ForDay{Weekday: fd.Weekday})
+	}
+
+	switch {
+	case offset != 0 && len(days) == 1 && len(rr.BySetPos) == 0:
+	case offset == 0 && len(rr.BySetPos) == 1:
+		offset = int(rr.BySetPos[0])
+	default:
+		ce.Add("BYDAY=%s can not be represented", rr.ByDay)
+	}
+
+	return days, offset
+}
+
+// clampedMonthDay returns the day of the month for the rule, the reverse
+// of clampedMonthDayInto. Days that don't exist in every month would be moved
+// by Outlook, so they're only accepted in their clamped form. BYMONTHDAY=-1
+// is the last day, which Outlook writes as day 31.
+func (rr *RecurringRule) clampedMonthDay(month int, ce *ConversionError) int {
+	days := rr.ByMonthDay
+
+	shortest := shortestMonth(month)
+
+	switch {
+	case len(days) == 0 && len(rr.BySetPos) == 0:
+		if rr.DtStart.Day() <= shortest {
+			return rr.DtStart.Day()
+		}
+	case len(days) == 1 && len(rr.BySetPos) == 0:
+		if days[0] == -1 {
+			return 31
+		}
+		if days[0] > 0 && int(days[0]) <= shortest {
+			return int(days[0])
+		}
+	case len(days) > 0 && len(rr.BySetPos) == 1 && rr.BySetPos[0] == -1 && int(days[0]) == shortest:
+		for i, d := range days {
+			if int(d) != shortest+i {
+				break
+			}
+			if i == len(days)-1 {
+				return int(d)
+			}
+		}
+	}
+
+	if len(days) == 0 {
+		ce.Add("day %d of the month can not be represented", rr.DtStart.Day())
+	} else {
+		ce.Add("BYMONTHDAY=%s can not be represented", listOfIntsToCSV(days))
+	}
+	return 0
+}
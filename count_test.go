@@ -0,0 +1,131 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+var countFrequencies = []string{"YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY", "SECONDLY"}
+
+// countSeries returns every occurrence of a rule, to compare windows with.
+func countSeries(t *testing.T, value string) (*RecurringRule, []time.Time) {
+	rule, err := Parse(value)
+	if err != nil {
+		t.Fatal(value, err)
+	}
+
+	var results []time.Time
+	var event time.Time
+	iter := rule.Iterator().HardLimit(100000)
+	for iter.Step(&event) {
+		results = append(results, event)
+	}
+	return rule, results
+}
+
+func Test_Count_After(t *testing.T) {
+	// The last five of ten, not ten after the fifth.
+	for _, freq := range countFrequencies {
+		rule, series := countSeries(t, "DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ="+freq+";COUNT=10")
+		if len(series) != 10 {
+			t.Fatal(freq, "expected 10 occurrences, got", len(series))
+		}
+
+		var results []time.Time
+		var event time.Time
+		iter := rule.Iterator().After(series[4])
+		for iter.Step(&event) {
+			results = append(results, event)
+		}
+
+		if len(results) != 5 {
+			t.Log(freq, "expected 5 occurrences after the fifth, got", len(results))
+			t.Fail()
+			continue
+		}
+		for i, value := range results {
+			if !value.Equal(series[5+i]) {
+				t.Log(freq, "expected", series[5+i], "got", value)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func Test_Count_Between(t *testing.T) {
+	// A window past the end of the series is empty.
+	for _, freq := range countFrequencies {
+		rule, series := countSeries(t, "DTSTART;TZID=Europe/Berlin:20240101T090000\nRRULE:FREQ="+freq+";INTERVAL=2;COUNT=4")
+
+		var event time.Time
+		iter := rule.Iterator().Between(series[1], series[3].Add(24*366*time.Hour))
+		var count int
+		for iter.Step(&event) {
+			count += 1
+		}
+		if count != 2 {
+			t.Log(freq, "expected the last 2 occurrences, got", count)
+			t.Fail()
+		}
+
+		iter = rule.Iterator().After(series[3])
+		if iter.Step(&event) {
+			t.Log(freq, "expected nothing after the last occurrence, got", event)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Count_Limit(t *testing.T) {
+	// Limit caps what's returned, COUNT where the series ends.
+	for _, freq := range countFrequencies {
+		rule, series := countSeries(t, "DTSTART;TZID=UTC:20240101T000000\nRRULE:FREQ="+freq+";COUNT=10")
+
+		var event time.Time
+		iter := rule.Iterator().After(series[2]).Limit(3)
+		for _, expected := range series[3:6] {
+			if !iter.Step(&event) || !event.Equal(expected) {
+				t.Log(freq, "expected", expected, "got", event)
+				t.Fail()
+			}
+		}
+		if iter.Step(&event) || iter.ReturnCounter != 3 {
+			t.Log(freq, "Limit didn't stop it", iter.ReturnCounter)
+			t.Fail()
+		}
+
+		iter = rule.Iterator().After(series[7]).Limit(5)
+		var count int
+		for iter.Step(&event) {
+			count += 1
+		}
+		if count != 2 {
+			t.Log(freq, "COUNT should end it before Limit, got", count)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Count_Exceptions(t *testing.T) {
+	// Excluded dates don't count, so the series still has five occurrences
+	// and ends a day later.
+	rule, series := countSeries(t,
+		"DTSTART;TZID=UTC:20240101T090000\n"+
+			"RRULE:FREQ=DAILY;COUNT=5\n"+
+			"EXDATE;TZID=UTC:20240102T090000",
+	)
+	if len(series) != 5 || !series[4].Equal(time.Date(2024, time.January, 6, 9, 0, 0, 0, time.UTC)) {
+		t.Fatal("unexpected series", series)
+	}
+
+	var event time.Time
+	var count int
+	iter := rule.Iterator().After(time.Date(2024, time.January, 3, 12, 0, 0, 0, time.UTC))
+	for iter.Step(&event) {
+		count += 1
+	}
+	if count != 3 {
+		t.Log("expected the 4th to the 6th, got", count)
+		t.Fail()
+	}
+}
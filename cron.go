@@ -0,0 +1,441 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Vixie cron expressions have five fields (minute hour day-of-month month
+// day-of-week), some implementations add a leading seconds field. When both
+// day-of-month and day-of-week are restricted (neither starts with "*"),
+// cron runs when EITHER matches, which is why ParseCron returns a RuleSet.
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronDayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if value, ok := names[strings.ToUpper(s)]; ok {
+		return value, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// parseCronField expands a single cron field (lists, ranges, steps and
+// names) into its sorted, de-duplicated values.
+func parseCronField(field string, min, max int, names map[string]int) ([]int16, error) {
+	var set = map[int]bool{}
+
+	for _, item := range strings.Split(field, ",") {
+		var step int = 1
+		var low, high int
+		var err error
+
+		// A step makes a single value the start of a range to max, "0/1"
+		// included.
+		stepped := strings.Contains(item, "/")
+		if stepped {
+			parts := strings.SplitN(item, "/", 2)
+			step, err = strconv.Atoi(parts[1])
+			if err != nil || step < 1 {
+				return nil, BadFormatError(field)
+			}
+			item = parts[0]
+		}
+
+		switch {
+		case item == "*":
+			low, high = min, max
+		case strings.Contains(item, "-"):
+			parts := strings.SplitN(item, "-", 2)
+			if low, err = parseCronValue(parts[0], names); err != nil {
+				return nil, BadFormatError(field)
+			}
+			if high, err = parseCronValue(parts[1], names); err != nil {
+				return nil, BadFormatError(field)
+			}
+		default:
+			if low, err = parseCronValue(item, names); err != nil {
+				return nil, BadFormatError(field)
+			}
+			high = low
+			if stepped {
+				high = max
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return nil, BadFormatError(field)
+		}
+
+		for v := low; v <= high; v += step {
+			set[v] = true
+		}
+	}
+
+	var results []int16
+	for v := range set {
+		results = append(results, int16(v))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+
+	return results, nil
+}
+
+func isFullRange(values []int16, min, max int) bool {
+	return len(values) == max-min+1
+}
+
+func cronWeekdays(values []int16) []ForDay {
+	var seen = map[int16]bool{}
+	var results []ForDay
+
+	for _, v := range values {
+		// Both 0 and 7 are Sunday.
+		v = v % 7
+		if !seen[v] {
+			seen[v] = true
+			results = append(results, ForDay{Weekday: time.Weekday(v)})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Weekday < results[j].Weekday })
+
+	return results
+}
+
+// ParseCron converts a 5 or 6 field cron expression (or one of the @daily
+// style macros) into a RuleSet. The rules start at midnight of the day of
+// from, in its location.
+func ParseCron(expr string, from time.Time) (RuleSet, error) {
+	expr = strings.TrimSpace(expr)
+
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	} else if strings.HasPrefix(expr, "@") {
+		return nil, &ConversionError{Format: "cron", Problems: []string{fmt.Sprintf("%s has no RRULE equivalent", expr)}}
+	}
+
+	fields := strings.Fields(expr)
+
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	} else if len(fields) != 6 {
+		return nil, BadFormatError(expr)
+	}
+
+	seconds, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	minutes, err := parseCronField(fields[1], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := parseCronField(fields[2], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+	monthDays, err := parseCronField(fields[3], 1, 31, nil)
+	if err != nil {
+		return nil, err
+	}
+	months, err := parseCronField(fields[4], 1, 12, cronMonthNames)
+	if err != nil {
+		return nil, err
+	}
+	weekDays, err := parseCronField(fields[5], 0, 7, cronDayNames)
+	if err != nil {
+		return nil, err
+	}
+
+	base := RecurringRule{
+		DtStart:       time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()),
+		Frequency:     DAILY,
+		Interval:      1,
+		WorkWeekStart: time.Monday,
+		BySecond:      seconds,
+		ByMinute:      minutes,
+		ByHour:        hours,
+	}
+
+	if !isFullRange(months, 1, 12) {
+		base.ByMonth = months
+	}
+
+	days := cronWeekdays(weekDays)
+	if len(days) == 7 {
+		days = nil
+	}
+
+	if isFullRange(monthDays, 1, 31) {
+		monthDays = nil
+	}
+
+	domRestricted := !strings.HasPrefix(fields[3], "*")
+	dowRestricted := !strings.HasPrefix(fields[5], "*")
+
+	if domRestricted && dowRestricted {
+		byMonthDay := base.Clone()
+		byMonthDay.ByMonthDay = monthDays
+
+		byDay := base.Clone()
+		byDay.ByDay = days
+
+		return RuleSet{byMonthDay, byDay}, nil
+	}
+
+	base.ByMonthDay = monthDays
+	base.ByDay = days
+
+	return RuleSet{&base}, nil
+}
+
+// formatCronList writes values as cron ranges, "*" when they cover every
+// value between min and max.
+func formatCronList(values []int16, min, max int) string {
+	sorted := append([]int16{}, values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var unique []int16
+	for _, v := range sorted {
+		if len(unique) == 0 || unique[len(unique)-1] != v {
+			unique = append(unique, v)
+		}
+	}
+
+	if len(unique) == 0 || isFullRange(unique, min, max) {
+		return "*"
+	}
+
+	var parts []string
+
+	for i := 0; i < len(unique); {
+		j := i
+		for j+1 < len(unique) && unique[j+1] == unique[j]+1 {
+			j += 1
+		}
+
+		if j-i >= 2 {
+			parts = append(parts, fmt.Sprintf("%d-%d", unique[i], unique[j]))
+		} else {
+			for k := i; k <= j; k += 1 {
+				parts = append(parts, fmt.Sprintf("%d", unique[k]))
+			}
+		}
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// alignedValues returns the values of a unit (hours, minutes, seconds)
+// that an interval starting at start hits, if the interval repeats in the
+// same place every larger unit.
+func alignedValues(start, interval, size int) ([]int16, bool) {
+	if size%interval != 0 {
+		return nil, false
+	}
+
+	var results []int16
+	for v := start % interval; v < size; v += interval {
+		results = append(results, int16(v))
+	}
+	return results, true
+}
+
+func intersectInt16(a, b []int16) []int16 {
+	if len(b) == 0 {
+		return a
+	}
+
+	var results []int16
+	for _, v := range a {
+		for _, w := range b {
+			if v == w {
+				results = append(results, v)
+				break
+			}
+		}
+	}
+	return results
+}
+
+// cronFields computes the seconds, minutes, hours, day-of-month, month and
+// day-of-week values for a rule, shared by the cron style formatters. UNTIL
+// is left to the callers, some formats have a year field.
+type cronFields struct {
+	seconds   []int16
+	minutes   []int16
+	hours     []int16
+	monthDays []int16
+	months    []int16
+	weekDays  []ForDay
+}
+
+func (rr *RecurringRule) cronFields(ce *ConversionError) cronFields {
+	var cf cronFields
+
+	if rr.Count > 0 {
+		ce.Add("COUNT can not be represented")
+	}
+	if len(rr.ExceptionsToRule) > 0 {
+		ce.Add("EXDATE can not be represented")
+	}
+	if len(rr.ByYearDay) > 0 {
+		ce.Add("BYYEARDAY can not be represented")
+	}
+	if len(rr.ByWeekNo) > 0 {
+		ce.Add("BYWEEKNO can not be represented")
+	}
+
+	interval := rr.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	if interval > 1 && rr.Frequency <= DAILY {
+		ce.Add("INTERVAL=%d with FREQ=%s can not be represented", interval, rr.Frequency)
+	}
+
+	all := func(min, max int) []int16 {
+		var results []int16
+		for v := min; v <= max; v += 1 {
+			results = append(results, int16(v))
+		}
+		return results
+	}
+
+	stepped := func(start, size int, by []int16) []int16 {
+		values, ok := alignedValues(start, interval, size)
+		if !ok {
+			ce.Add("INTERVAL=%d with FREQ=%s does not repeat evenly", interval, rr.Frequency)
+		}
+		return intersectInt16(values, by)
+	}
+
+	cf.seconds = rr.BySecond
+	cf.minutes = rr.ByMinute
+	cf.hours = rr.ByHour
+
+	switch rr.Frequency {
+	case SECONDLY:
+		cf.seconds = stepped(rr.DtStart.Second(), 60, rr.BySecond)
+		if len(cf.minutes) == 0 {
+			cf.minutes = all(0, 59)
+		}
+		if len(cf.hours) == 0 {
+			cf.hours = all(0, 23)
+		}
+	case MINUTELY:
+		cf.minutes = stepped(rr.DtStart.Minute(), 60, rr.ByMinute)
+		if len(cf.hours) == 0 {
+			cf.hours = all(0, 23)
+		}
+	case HOURLY:
+		cf.hours = stepped(rr.DtStart.Hour(), 24, rr.ByHour)
+	}
+
+	if len(cf.seconds) == 0 {
+		cf.seconds = []int16{int16(rr.DtStart.Second())}
+	}
+	if len(cf.minutes) == 0 {
+		cf.minutes = []int16{int16(rr.DtStart.Minute())}
+	}
+	if len(cf.hours) == 0 {
+		cf.hours = []int16{int16(rr.DtStart.Hour())}
+	}
+
+	cf.months = rr.ByMonth
+	cf.monthDays = rr.ByMonthDay
+	cf.weekDays = rr.ByDay
+
+	switch rr.Frequency {
+	case WEEKLY:
+		if len(cf.weekDays) == 0 {
+			cf.weekDays = []ForDay{{Weekday: rr.DtStart.Weekday()}}
+		}
+	case MONTHLY:
+		if len(cf.weekDays) == 0 && len(cf.monthDays) == 0 {
+			cf.monthDays = []int16{int16(rr.DtStart.Day())}
+		}
+	case YEARLY:
+		if len(cf.weekDays) == 0 && len(cf.monthDays) == 0 {
+			cf.monthDays = []int16{int16(rr.DtStart.Day())}
+			if len(cf.months) == 0 {
+				cf.months = []int16{int16(rr.DtStart.Month())}
+			}
+		}
+	}
+
+	return cf
+}
+
+// CronString converts the rule into a cron expression, five fields when
+// every occurrence is on a whole minute and six (with seconds) otherwise.
+func (rr *RecurringRule) CronString() (string, error) {
+	ce := &ConversionError{Format: "cron"}
+
+	if len(rr.BySetPos) > 0 {
+		ce.Add("BYSETPOS can not be represented")
+	}
+	if !rr.Until.Equal(EmptyTime) {
+		ce.Add("UNTIL can not be represented")
+	}
+
+	cf := rr.cronFields(ce)
+
+	if len(cf.monthDays) > 0 && len(cf.weekDays) > 0 {
+		ce.Add("BYMONTHDAY and BYDAY match both in an RRULE but either in cron")
+	}
+
+	for _, day := range cf.monthDays {
+		if day < 1 {
+			ce.Add("BYMONTHDAY=%d can not be represented", day)
+		}
+	}
+
+	var weekDays []int16
+	for _, fd := range cf.weekDays {
+		if fd.Offset != 0 {
+			ce.Add("BYDAY=%s can not be represented", fd)
+		}
+		weekDays = append(weekDays, int16(fd.Weekday))
+	}
+
+	if err := ce.Err(); err != nil {
+		return "", err
+	}
+
+	fields := []string{
+		formatCronList(cf.minutes, 0, 59),
+		formatCronList(cf.hours, 0, 23),
+		formatCronList(cf.monthDays, 1, 31),
+		formatCronList(cf.months, 1, 12),
+		formatCronList(weekDays, 0, 6),
+	}
+
+	if len(cf.seconds) != 1 || cf.seconds[0] != 0 {
+		fields = append([]string{formatCronList(cf.seconds, 0, 59)}, fields...)
+	}
+
+	return strings.Join(fields, " "), nil
+}
@@ -0,0 +1,236 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Cron_Parse(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	rules, err := ParseCron("*/15 9-17 * * 1-5", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rules) != 1 {
+		t.Fatal("Expected a single rule", rules)
+	}
+
+	expected, _ := Parse(
+		"RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0,15,30,45;" +
+			"BYHOUR=9,10,11,12,13,14,15,16,17;BYDAY=MO,TU,WE,TH,FR",
+	)
+	expected.DtStart = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	if !rules[0].Equal(expected) {
+		t.Log("Rules don't match", rules[0])
+		t.Fail()
+	}
+
+	var event time.Time
+	iter := rules.Iterator().After(from)
+
+	targets := []time.Time{
+		time.Date(2024, time.March, 1, 12, 15, 0, 0, time.UTC),
+		time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC),
+	}
+
+	for _, target := range targets {
+		if !iter.Step(&event) || !event.Equal(target) {
+			t.Log("Unexpected occurrence", event, target)
+			t.Fail()
+		}
+	}
+
+	// 17:45 on Friday is followed by 9:00 on Monday.
+	iter = rules.Iterator().After(time.Date(2024, time.March, 1, 17, 45, 0, 0, time.UTC))
+	if !iter.Step(&event) || !event.Equal(time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC)) {
+		t.Log("Failed to skip the weekend", event)
+		t.Fail()
+	}
+}
+
+func Test_Cron_OrSemantics(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// The 1st and 15th of every month, and every Monday.
+	rules, err := ParseCron("0 0 1,15 * MON", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rules) != 2 {
+		t.Fatal("Expected two rules for day-of-month OR day-of-week", rules)
+	}
+
+	// January 1st 2024 is a Monday, it's only returned once.
+	targets := []time.Time{
+		time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 22, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	var index int
+
+	iter := rules.Iterator().Limit(len(targets))
+	for iter.Step(&event) {
+		if !event.Equal(targets[index]) {
+			t.Log("Unexpected occurrence", event, targets[index])
+			t.Fail()
+		}
+		index += 1
+	}
+
+	if index != len(targets) {
+		t.Log("Failed to return enough results", index)
+		t.Fail()
+	}
+
+	// A day-of-month starting with * is ANDed, like Vixie cron.
+	rules, _ = ParseCron("0 0 */2 * MON", from)
+	if len(rules) != 1 || len(rules[0].ByMonthDay) != 16 || len(rules[0].ByDay) != 1 {
+		t.Log("Expected AND semantics", rules)
+		t.Fail()
+	}
+}
+
+func Test_Cron_Fields(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	rules, err := ParseCron("30 0 0 1 JAN-MAR,DEC 0,7", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rules) != 2 {
+		t.Fatal("Expected two rules", rules)
+	}
+
+	if rules[0].BySecond[0] != 30 || len(rules[0].ByMonth) != 4 {
+		t.Log("Failed to parse fields", rules[0])
+		t.Fail()
+	}
+
+	if len(rules[1].ByDay) != 1 || rules[1].ByDay[0].Weekday != time.Sunday {
+		t.Log("0 and 7 are both Sunday", rules[1].ByDay)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "@reboot"} {
+		if _, err := ParseCron(bad, from); err == nil {
+			t.Log("Expected error for", bad)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Cron_ToString(t *testing.T) {
+	var cases = map[string]string{
+		"RRULE:FREQ=DAILY":  "0 9 * * *",
+		"RRULE:FREQ=WEEKLY": "0 9 * * 2",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=8,17":   "0 8,17 * * 1-5",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=1,15":                   "0 9 1,15 * *",
+		"RRULE:FREQ=YEARLY":                                    "0 9 2 9 *",
+		"RRULE:FREQ=MINUTELY;INTERVAL=15;BYHOUR=9,10,11,12":    "0,15,30,45 9-12 * * *",
+		"RRULE:FREQ=HOURLY;INTERVAL=6":                         "0 3,9,15,21 * * *",
+		"RRULE:FREQ=DAILY;BYSECOND=30":                         "30 0 9 * * *",
+		"RRULE:FREQ=YEARLY;BYMONTH=1,2,3;BYDAY=MO":             "0 9 * 1-3 1",
+		"RRULE:FREQ=SECONDLY;INTERVAL=20;BYMINUTE=0;BYHOUR=12": "0,20,40 0 12 * * *",
+	}
+
+	for value, expected := range cases {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		result, err := rule.CronString()
+		if err != nil || result != expected {
+			t.Log("Failed to convert", value, result, err)
+			t.Fail()
+		}
+	}
+
+	var unsupported = []string{
+		"RRULE:FREQ=DAILY;COUNT=10",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2",
+		"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+		"RRULE:FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"RRULE:FREQ=MINUTELY;INTERVAL=7",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		if _, err := rule.CronString(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
+
+func Test_RuleSet_Window(t *testing.T) {
+	first, _ := Parse("DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=DAILY;INTERVAL=2")
+	second, _ := Parse("DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=DAILY;INTERVAL=3")
+
+	var event time.Time
+	var results []time.Time
+
+	iter := RuleSet{first, second}.Iterator().Between(
+		time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+	)
+
+	for iter.Step(&event) {
+		results = append(results, event)
+	}
+
+	// Days 3, 4, 5, 7 (day 7 is in both rules).
+	if len(results) != 4 || results[0].Day() != 3 || results[3].Day() != 7 {
+		t.Log("Unexpected results", results)
+		t.Fail()
+	}
+}
+
+func Test_Cron_Steps(t *testing.T) {
+	// A step after a single value runs to the end of the range, whatever
+	// the step is.
+	var cases = map[string][]int16{
+		"0/1":   {0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		"*/1":   {0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		"4/1":   {4, 5, 6, 7, 8, 9},
+		"0/3":   {0, 3, 6, 9},
+		"2-6/2": {2, 4, 6},
+		"7":     {7},
+	}
+
+	for field, expected := range cases {
+		values, err := parseCronField(field, 0, 9, nil)
+		if err != nil || len(values) != len(expected) {
+			t.Log("Unexpected values", field, values, err)
+			t.Fail()
+			continue
+		}
+		for i := range values {
+			if values[i] != expected[i] {
+				t.Log("Unexpected values", field, values)
+				t.Fail()
+				break
+			}
+		}
+	}
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rules, err := ParseQuartz("0 0/1 * * * ?", from)
+	if err != nil || len(rules) != 1 || len(rules[0].ByMinute) != 60 {
+		t.Log("0/1 is every minute", rules, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,133 @@
+package rrule
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The version of the cursor format, cursors of other versions are
+// rejected.
+const cursorVersion = 3
+
+// ErrCursorRuleChanged is returned when resuming a cursor with a rule other
+// than the one it was made with.
+var ErrCursorRuleChanged = errors.New("cursor is for a different rule")
+
+// cursor is what a cursor string holds: where the iterator is, the
+// occurrences left from its last period and the window it was made with.
+type cursor struct {
+	Version  int         `json:"v"`
+	Rule     string      `json:"rule"`
+	Started  bool        `json:"started,omitempty"`
+	Period   int         `json:"period"`
+	Returned int         `json:"returned"`
+	Series   int         `json:"series,omitempty"`
+	Seeked   bool        `json:"seeked,omitempty"`
+	Buffer   []time.Time `json:"buffer,omitempty"`
+
+	After   *time.Time `json:"after,omitempty"`
+	Before  *time.Time `json:"before,omitempty"`
+	Reverse bool       `json:"reverse,omitempty"`
+	Last    *time.Time `json:"last,omitempty"`
+	Lowest  int        `json:"lowest,omitempty"`
+}
+
+// Cursor returns an opaque string that ResumeIterator continues from, for
+// paging through occurrences without starting from DTSTART each page:
+//
+//	iter := rule.Iterator().After(from).Limit(50)
+//	for iter.Step(&event) {
+//		...
+//	}
+//	next := iter.Cursor()
+//
+// Limit, HardLimit and Context aren't part of it.
+func (ri *RecurrenceIterator) Cursor() string {
+	c := cursor{
+		Version:  cursorVersion,
+		Rule:     ruleHash(ri.rule),
+		Started:  ri.started,
+		Period:   ri.iterCounter,
+		Returned: ri.ReturnCounter,
+		Series:   ri.series,
+		Seeked:   ri.seeked,
+		Buffer:   ri.iterBuffer,
+		Reverse:  ri.reverse,
+		Lowest:   ri.lowest,
+	}
+	if ri.UseUserAfter {
+		c.After = &ri.UserAfter
+	}
+	if ri.UseUserBefore {
+		c.Before = &ri.UserBefore
+	}
+	if ri.reverse && ri.started {
+		c.Last = &ri.last
+	}
+
+	// Only times and numbers, which always encode.
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ResumeIterator returns an iterator that continues where the one that
+// made the cursor stopped. The rule has to be the same, otherwise it's
+// ErrCursorRuleChanged. Limit counts from the cursor, so the same Limit
+// gives the next page.
+func (rr *RecurringRule) ResumeIterator(value string) (*RecurrenceIterator, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid cursor: %s", err))
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid cursor: %s", err))
+	}
+	if c.Version != cursorVersion {
+		return nil, errors.New(fmt.Sprintf("unsupported cursor version %d", c.Version))
+	}
+	if c.Rule != ruleHash(rr) {
+		return nil, ErrCursorRuleChanged
+	}
+
+	ri := rr.Iterator()
+	loc := ri.rule.DtStart.Location()
+
+	ri.started = c.Started
+	ri.iterCounter = c.Period
+	ri.ReturnCounter = c.Returned
+	ri.resumed = c.Returned
+	ri.series = c.Series
+	ri.seeked = c.Seeked
+	for _, t := range c.Buffer {
+		ri.buffer = append(ri.buffer, t.In(loc))
+	}
+	ri.iterBuffer = ri.buffer
+
+	if c.After != nil {
+		ri.After(*c.After)
+	}
+	if c.Before != nil {
+		ri.Before(*c.Before)
+	}
+	ri.reverse = c.Reverse
+	ri.lowest = c.Lowest
+	if c.Last != nil {
+		ri.last = *c.Last
+	}
+
+	return ri, nil
+}
+
+// ruleHash identifies the rule exactly. Fingerprint isn't enough, rules
+// with the same occurrences can still divide them into different periods.
+func ruleHash(rr *RecurringRule) string {
+	sum := sha256.Sum256([]byte(rr.String()))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,110 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// pages steps through the iterator a page at a time, resuming each page from
+// the cursor of the last.
+func pages(t *testing.T, rule *RecurringRule, first *RecurrenceIterator, size int) []time.Time {
+	var results []time.Time
+	var event time.Time
+
+	iter := first.Limit(size)
+	for {
+		var count int
+		for iter.Step(&event) {
+			results = append(results, event)
+			count += 1
+		}
+		if count < size {
+			return results
+		}
+
+		var err error
+		iter, err = rule.ResumeIterator(iter.Cursor())
+		if err != nil {
+			t.Fatal(err)
+		}
+		iter.Limit(size)
+	}
+}
+
+func Test_Cursor_Pages(t *testing.T) {
+	// Pages of 7 split the periods, and the sets of four days a month.
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"EXDATE;TZID=America/New_York:19971104T090000\n" +
+			"RRULE:FREQ=MONTHLY;COUNT=30;BYDAY=TU,TH;BYSETPOS=1,2,-2,-1",
+	)
+
+	var all []time.Time
+	var event time.Time
+	iter := rule.Iterator()
+	for iter.Step(&event) {
+		all = append(all, event)
+	}
+
+	for _, size := range []int{1, 3, 7, 30, 50} {
+		paged := pages(t, rule, rule.Iterator(), size)
+		if len(paged) != len(all) {
+			t.Log(size, "expected", len(all), "occurrences, got", len(paged))
+			t.Fail()
+			continue
+		}
+		for i := range all {
+			if !paged[i].Equal(all[i]) || paged[i].Location() != all[i].Location() {
+				t.Log(size, "expected", all[i], "got", paged[i])
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func Test_Cursor_Window(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=DAILY")
+	a := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	forward := pages(t, rule, rule.Iterator().Between(a, b), 10)
+	if len(forward) != 31 || !forward[30].Equal(time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC)) {
+		t.Log("expected March", len(forward))
+		t.Fail()
+	}
+
+	backward := pages(t, rule, rule.Iterator().Between(a, b).Reverse(), 10)
+	if len(backward) != 31 || !backward[0].Equal(forward[30]) || !backward[30].Equal(forward[0]) {
+		t.Log("expected March backwards", len(backward))
+		t.Fail()
+	}
+}
+
+func Test_Cursor_RuleChanged(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=WEEKLY;WKST=SU")
+
+	var event time.Time
+	iter := rule.Iterator()
+	iter.Step(&event)
+	cursor := iter.Cursor()
+
+	if _, err := rule.Clone().ResumeIterator(cursor); err != nil {
+		t.Log("a copy of the rule should resume", err)
+		t.Fail()
+	}
+
+	// The same occurrences, but weeks from Monday.
+	changed := rule.Clone()
+	changed.WorkWeekStart = time.Monday
+	if _, err := changed.ResumeIterator(cursor); !errors.Is(err, ErrCursorRuleChanged) {
+		t.Log("expected the change to be found", err)
+		t.Fail()
+	}
+
+	if _, err := rule.ResumeIterator("not a cursor"); err == nil {
+		t.Log("expected an invalid cursor to fail")
+		t.Fail()
+	}
+}
@@ -171,6 +171,40 @@ func ParseDuration(s string) (time.Duration, error) {
 	return time.Duration(durationSeconds) * time.Second, nil
 }
 
+// DurationToString writes a duration the way ParseDuration reads it, such
+// as PT1H30M or P1DT12H. Fractions of a second are dropped.
+func DurationToString(d time.Duration) string {
+	var result bytes.Buffer
+
+	if d < 0 {
+		result.WriteString("-")
+		d = -d
+	}
+	result.WriteString("P")
+
+	seconds := int64(d / time.Second)
+	days := seconds / (60 * 60 * 24)
+	seconds -= days * 60 * 60 * 24
+
+	if days > 0 {
+		fmt.Fprintf(&result, "%dD", days)
+	}
+	if seconds > 0 || days == 0 {
+		result.WriteString("T")
+		if h := seconds / 3600; h > 0 {
+			fmt.Fprintf(&result, "%dH", h)
+		}
+		if m := seconds % 3600 / 60; m > 0 {
+			fmt.Fprintf(&result, "%dM", m)
+		}
+		if s := seconds % 60; s > 0 || seconds == 0 {
+			fmt.Fprintf(&result, "%dS", s)
+		}
+	}
+
+	return result.String()
+}
+
 func DateTimeToString(t time.Time) string {
 	if t.Location() == time.UTC {
 		return fmt.Sprintf("%04d%02d%02dT%02d%02d%02dZ",
@@ -113,3 +113,25 @@ func Test_DateTimeToString(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_DurationToString(t *testing.T) {
+	var cases = map[time.Duration]string{
+		90 * time.Minute:             "PT1H30M",
+		36 * time.Hour:               "P1DT12H",
+		48 * time.Hour:               "P2D",
+		0:                            "PT0S",
+		-(time.Hour + 5*time.Second): "-PT1H5S",
+	}
+
+	for d, expected := range cases {
+		if result := DurationToString(d); result != expected {
+			t.Log("Unexpected duration", d, result)
+			t.Fail()
+		}
+
+		if parsed, _ := ParseDuration(expected); parsed != d {
+			t.Log("Duration doesn't round trip", expected, parsed)
+			t.Fail()
+		}
+	}
+}
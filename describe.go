@@ -0,0 +1,263 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Locale supplies the words Describe builds a sentence from. Every method
+// returns a phrase for one part of the rule, the values are passed as they
+// are in the rule so each language can pick its own grammar.
+type Locale interface {
+	// Frequency describes FREQ and INTERVAL, "every 2 weeks".
+	Frequency(freq FrequencyValue, interval int) string
+	// Months describes BYMONTH, "in January and March".
+	Months(months []time.Month) string
+	// Weekdays describes BYDAY, "on the first Monday".
+	Weekdays(days []ForDay) string
+	// Numbers describes the numeric parts BYMONTHDAY, BYYEARDAY, BYWEEKNO,
+	// BYHOUR, BYMINUTE, BYSECOND and BYSETPOS. freq is the frequency of
+	// the rule, BYSETPOS applies to each of its periods.
+	Numbers(part string, values []int, freq FrequencyValue) string
+	// WeekStart describes WKST, it's only used when it isn't Monday.
+	WeekStart(day time.Weekday) string
+	Count(count int) string
+	Until(until time.Time) string
+	// Exceptions describes the number of EXDATEs.
+	Exceptions(count int) string
+}
+
+var locales = map[string]Locale{
+	"en": English,
+	"de": German,
+	"fr": French,
+	"es": Spanish,
+}
+
+// LookupLocale returns the Locale for a language, "en", "de", "fr" or "es".
+func LookupLocale(language string) (Locale, bool) {
+	locale, ok := locales[strings.ToLower(language)]
+	return locale, ok
+}
+
+// Describe returns a sentence describing every part of the rule, such as
+// "Every 2 weeks on Monday and Wednesday, 10 times". DtStart isn't
+// described, only the parts of the RRULE and the number of EXDATEs.
+func (rr *RecurringRule) Describe(locale Locale) string {
+	interval := rr.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	words := []string{locale.Frequency(rr.Frequency, interval)}
+
+	if len(rr.ByMonth) > 0 {
+		var months []time.Month
+		for _, m := range rr.ByMonth {
+			months = append(months, time.Month(m))
+		}
+		words = append(words, locale.Months(months))
+	}
+
+	numbers := []struct {
+		part   string
+		values []int16
+	}{
+		{"BYWEEKNO", rr.ByWeekNo},
+		{"BYYEARDAY", rr.ByYearDay},
+		{"BYMONTHDAY", rr.ByMonthDay},
+	}
+
+	for _, n := range numbers {
+		if len(n.values) > 0 {
+			words = append(words, locale.Numbers(n.part, int16sToInt(n.values), rr.Frequency))
+		}
+	}
+
+	if len(rr.ByDay) > 0 {
+		words = append(words, locale.Weekdays(rr.ByDay))
+	}
+
+	numbers = []struct {
+		part   string
+		values []int16
+	}{
+		{"BYHOUR", rr.ByHour},
+		{"BYMINUTE", rr.ByMinute},
+		{"BYSECOND", rr.BySecond},
+		{"BYSETPOS", rr.BySetPos},
+	}
+
+	for _, n := range numbers {
+		if len(n.values) > 0 {
+			words = append(words, locale.Numbers(n.part, int16sToInt(n.values), rr.Frequency))
+		}
+	}
+
+	if rr.WorkWeekStart != time.Monday {
+		words = append(words, locale.WeekStart(rr.WorkWeekStart))
+	}
+
+	sentence := []string{strings.Join(words, " ")}
+
+	if rr.Count > 0 {
+		sentence = append(sentence, locale.Count(rr.Count))
+	}
+	if !rr.Until.Equal(EmptyTime) {
+		sentence = append(sentence, locale.Until(rr.Until.In(rr.DtStart.Location())))
+	}
+	if len(rr.ExceptionsToRule) > 0 {
+		sentence = append(sentence, locale.Exceptions(len(rr.ExceptionsToRule)))
+	}
+
+	return capitalize(strings.Join(sentence, ", "))
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// joinWords writes a list as "a, b and c" with the given word for "and".
+func joinWords(items []string, and string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + and + " " + items[len(items)-1]
+}
+
+func mapInts(values []int, f func(int) string) []string {
+	var results []string
+	for _, v := range values {
+		results = append(results, f(v))
+	}
+	return results
+}
+
+func plainNumber(n int) string {
+	return fmt.Sprintf("%d", n)
+}
+
+// English is read back by the phrase package, keep the two in step when
+// changing the wording.
+var English Locale = englishLocale{}
+
+type englishLocale struct{}
+
+var englishUnits = map[FrequencyValue]string{
+	YEARLY: "year", MONTHLY: "month", WEEKLY: "week", DAILY: "day",
+	HOURLY: "hour", MINUTELY: "minute", SECONDLY: "second",
+}
+
+var englishOrdinalWords = []string{"", "first", "second", "third", "fourth", "fifth"}
+
+// englishOrdinal writes 1st, 2nd, 3rd and so on.
+func englishOrdinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// englishNth writes positions, words are used up to fifth and negative
+// values count from the end: last, second to last.
+func englishNth(n int, words bool) string {
+	if n < 0 {
+		if n == -1 {
+			return "last"
+		}
+		return englishNth(-n, words) + " to last"
+	}
+	if words && n < len(englishOrdinalWords) {
+		return englishOrdinalWords[n]
+	}
+	return englishOrdinal(n)
+}
+
+func (englishLocale) Frequency(freq FrequencyValue, interval int) string {
+	if interval == 1 {
+		return "every " + englishUnits[freq]
+	}
+	return fmt.Sprintf("every %d %ss", interval, englishUnits[freq])
+}
+
+func (englishLocale) Months(months []time.Month) string {
+	var names []string
+	for _, m := range months {
+		names = append(names, m.String())
+	}
+	return "in " + joinWords(names, "and")
+}
+
+func (englishLocale) Weekdays(days []ForDay) string {
+	var names []string
+	for _, fd := range days {
+		if fd.Offset == 0 {
+			names = append(names, fd.Weekday.String())
+		} else {
+			names = append(names, "the "+englishNth(fd.Offset, true)+" "+fd.Weekday.String())
+		}
+	}
+	return "on " + joinWords(names, "and")
+}
+
+func (englishLocale) Numbers(part string, values []int, freq FrequencyValue) string {
+	nth := func(n int) string { return englishNth(n, false) }
+	plural := func(word string) string {
+		if len(values) > 1 {
+			return word + "s"
+		}
+		return word
+	}
+
+	switch part {
+	case "BYWEEKNO":
+		return "in the " + joinWords(mapInts(values, nth), "and") + " " + plural("week") + " of the year"
+	case "BYYEARDAY":
+		return "on the " + joinWords(mapInts(values, nth), "and") + " " + plural("day") + " of the year"
+	case "BYMONTHDAY":
+		return "on the " + joinWords(mapInts(values, nth), "and") + " " + plural("day") + " of the month"
+	case "BYHOUR":
+		return "at " + plural("hour") + " " + joinWords(mapInts(values, plainNumber), "and")
+	case "BYMINUTE":
+		return "at " + plural("minute") + " " + joinWords(mapInts(values, plainNumber), "and")
+	case "BYSECOND":
+		return "at " + plural("second") + " " + joinWords(mapInts(values, plainNumber), "and")
+	case "BYSETPOS":
+		words := mapInts(values, func(n int) string { return englishNth(n, true) })
+		return "only the " + joinWords(words, "and") + " " + plural("occurrence") + " in each " + englishUnits[freq]
+	}
+	return ""
+}
+
+func (englishLocale) WeekStart(day time.Weekday) string {
+	return "with weeks starting on " + day.String()
+}
+
+func (englishLocale) Count(count int) string {
+	if count == 1 {
+		return "once"
+	}
+	return fmt.Sprintf("%d times", count)
+}
+
+func (englishLocale) Until(until time.Time) string {
+	return "until " + until.Format("January 2, 2006")
+}
+
+func (englishLocale) Exceptions(count int) string {
+	if count == 1 {
+		return "except on 1 date"
+	}
+	return fmt.Sprintf("except on %d dates", count)
+}
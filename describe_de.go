@@ -0,0 +1,123 @@
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+var German Locale = germanLocale{}
+
+type germanLocale struct{}
+
+var germanWeekdays = []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}
+
+var germanMonths = []string{
+	"", "Januar", "Februar", "März", "April", "Mai", "Juni",
+	"Juli", "August", "September", "Oktober", "November", "Dezember",
+}
+
+var germanAdverbs = map[FrequencyValue]string{
+	YEARLY: "jährlich", MONTHLY: "monatlich", WEEKLY: "wöchentlich", DAILY: "täglich",
+	HOURLY: "stündlich", MINUTELY: "jede Minute", SECONDLY: "jede Sekunde",
+}
+
+var germanPlurals = map[FrequencyValue]string{
+	YEARLY: "Jahre", MONTHLY: "Monate", WEEKLY: "Wochen", DAILY: "Tage",
+	HOURLY: "Stunden", MINUTELY: "Minuten", SECONDLY: "Sekunden",
+}
+
+// Each period in the genitive, "jedes Monats".
+var germanPeriods = map[FrequencyValue]string{
+	YEARLY: "jedes Jahres", MONTHLY: "jedes Monats", WEEKLY: "jeder Woche", DAILY: "jedes Tages",
+	HOURLY: "jeder Stunde", MINUTELY: "jeder Minute", SECONDLY: "jeder Sekunde",
+}
+
+var germanOrdinalWords = []string{"", "ersten", "zweiten", "dritten", "vierten", "fünften"}
+
+// germanNth writes positions in the dative, "am ersten", "am 20.",
+// "am letzten" and "am vorletzten".
+func germanNth(n int, words bool) string {
+	switch {
+	case n == -1:
+		return "letzten"
+	case n == -2:
+		return "vorletzten"
+	case n < 0:
+		return fmt.Sprintf("%d.-letzten", -n)
+	case words && n < len(germanOrdinalWords):
+		return germanOrdinalWords[n]
+	}
+	return fmt.Sprintf("%d.", n)
+}
+
+func (germanLocale) Frequency(freq FrequencyValue, interval int) string {
+	if interval == 1 {
+		return germanAdverbs[freq]
+	}
+	return fmt.Sprintf("alle %d %s", interval, germanPlurals[freq])
+}
+
+func (germanLocale) Months(months []time.Month) string {
+	var names []string
+	for _, m := range months {
+		names = append(names, germanMonths[m])
+	}
+	return "im " + joinWords(names, "und")
+}
+
+func (germanLocale) Weekdays(days []ForDay) string {
+	var names []string
+	for _, fd := range days {
+		if fd.Offset == 0 {
+			names = append(names, germanWeekdays[fd.Weekday])
+		} else {
+			names = append(names, germanNth(fd.Offset, true)+" "+germanWeekdays[fd.Weekday])
+		}
+	}
+	return "am " + joinWords(names, "und")
+}
+
+func (germanLocale) Numbers(part string, values []int, freq FrequencyValue) string {
+	nth := func(n int) string { return germanNth(n, false) }
+
+	switch part {
+	case "BYWEEKNO":
+		return "in der " + joinWords(mapInts(values, nth), "und") + " Woche des Jahres"
+	case "BYYEARDAY":
+		return "am " + joinWords(mapInts(values, nth), "und") + " Tag des Jahres"
+	case "BYMONTHDAY":
+		return "am " + joinWords(mapInts(values, nth), "und") + " Tag des Monats"
+	case "BYHOUR":
+		return "um " + joinWords(mapInts(values, plainNumber), "und") + " Uhr"
+	case "BYMINUTE":
+		return "zur Minute " + joinWords(mapInts(values, plainNumber), "und")
+	case "BYSECOND":
+		return "zur Sekunde " + joinWords(mapInts(values, plainNumber), "und")
+	case "BYSETPOS":
+		words := mapInts(values, func(n int) string { return germanNth(n, true) })
+		return "nur am " + joinWords(words, "und") + " Termin " + germanPeriods[freq]
+	}
+	return ""
+}
+
+func (germanLocale) WeekStart(day time.Weekday) string {
+	return "mit Wochenbeginn am " + germanWeekdays[day]
+}
+
+func (germanLocale) Count(count int) string {
+	if count == 1 {
+		return "einmal"
+	}
+	return fmt.Sprintf("%d Mal", count)
+}
+
+func (germanLocale) Until(until time.Time) string {
+	return fmt.Sprintf("bis zum %d. %s %d", until.Day(), germanMonths[until.Month()], until.Year())
+}
+
+func (germanLocale) Exceptions(count int) string {
+	if count == 1 {
+		return "außer an 1 Termin"
+	}
+	return fmt.Sprintf("außer an %d Terminen", count)
+}
@@ -0,0 +1,134 @@
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+var Spanish Locale = spanishLocale{}
+
+type spanishLocale struct{}
+
+var spanishWeekdays = []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"}
+
+var spanishMonths = []string{
+	"", "enero", "febrero", "marzo", "abril", "mayo", "junio",
+	"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+}
+
+var spanishUnits = map[FrequencyValue]string{
+	YEARLY: "año", MONTHLY: "mes", WEEKLY: "semana", DAILY: "día",
+	HOURLY: "hora", MINUTELY: "minuto", SECONDLY: "segundo",
+}
+
+var spanishPlurals = map[FrequencyValue]string{
+	YEARLY: "años", MONTHLY: "meses", WEEKLY: "semanas", DAILY: "días",
+	HOURLY: "horas", MINUTELY: "minutos", SECONDLY: "segundos",
+}
+
+var spanishOrdinalWords = []string{"", "primer", "segundo", "tercer", "cuarto", "quinto"}
+var spanishFeminineWords = []string{"", "primera", "segunda", "tercera", "cuarta", "quinta"}
+
+// spanishNth writes a position with its noun and article, "el primer
+// lunes", "la última semana" or "el penúltimo día".
+func spanishNth(n int, noun string, feminine bool) string {
+	article, words, last, suffix := "el ", spanishOrdinalWords, "último", "º"
+	if feminine {
+		article, words, last, suffix = "la ", spanishFeminineWords, "última", "ª"
+	}
+
+	switch {
+	case n > 0 && n < len(words):
+		return article + words[n] + " " + noun
+	case n > 0:
+		return fmt.Sprintf("%s%d.%s %s", article, n, suffix, noun)
+	case n == -1:
+		return article + last + " " + noun
+	case n == -2:
+		return article + "pen" + last + " " + noun
+	}
+	return fmt.Sprintf("%s%d.%s %s contando desde el final", article, -n, suffix, noun)
+}
+
+func (spanishLocale) Frequency(freq FrequencyValue, interval int) string {
+	if interval == 1 {
+		return "cada " + spanishUnits[freq]
+	}
+	return fmt.Sprintf("cada %d %s", interval, spanishPlurals[freq])
+}
+
+func (spanishLocale) Months(months []time.Month) string {
+	var names []string
+	for _, m := range months {
+		names = append(names, spanishMonths[m])
+	}
+	return "en " + joinWords(names, "y")
+}
+
+func (spanishLocale) Weekdays(days []ForDay) string {
+	var names []string
+	for _, fd := range days {
+		if fd.Offset == 0 {
+			names = append(names, "el "+spanishWeekdays[fd.Weekday])
+		} else {
+			names = append(names, spanishNth(fd.Offset, spanishWeekdays[fd.Weekday], false))
+		}
+	}
+	return joinWords(names, "y")
+}
+
+func (spanishLocale) Numbers(part string, values []int, freq FrequencyValue) string {
+	switch part {
+	case "BYWEEKNO":
+		return joinWords(mapInts(values, func(n int) string {
+			if n > 0 {
+				return fmt.Sprintf("la semana %d", n)
+			}
+			return spanishNth(n, "semana", true)
+		}), "y") + " del año"
+	case "BYYEARDAY", "BYMONTHDAY":
+		period := " del mes"
+		if part == "BYYEARDAY" {
+			period = " del año"
+		}
+		return joinWords(mapInts(values, func(n int) string {
+			if n > 0 {
+				return fmt.Sprintf("el día %d", n)
+			}
+			return spanishNth(n, "día", false)
+		}), "y") + period
+	case "BYHOUR":
+		return "a las " + joinWords(mapInts(values, plainNumber), "y") + " h"
+	case "BYMINUTE":
+		return "en el minuto " + joinWords(mapInts(values, plainNumber), "y")
+	case "BYSECOND":
+		return "en el segundo " + joinWords(mapInts(values, plainNumber), "y")
+	case "BYSETPOS":
+		return "solo " + joinWords(mapInts(values, func(n int) string {
+			return spanishNth(n, "repetición", true)
+		}), "y") + " de cada " + spanishUnits[freq]
+	}
+	return ""
+}
+
+func (spanishLocale) WeekStart(day time.Weekday) string {
+	return "con semanas que empiezan el " + spanishWeekdays[day]
+}
+
+func (spanishLocale) Count(count int) string {
+	if count == 1 {
+		return "una vez"
+	}
+	return fmt.Sprintf("%d veces", count)
+}
+
+func (spanishLocale) Until(until time.Time) string {
+	return fmt.Sprintf("hasta el %d de %s de %d", until.Day(), spanishMonths[until.Month()], until.Year())
+}
+
+func (spanishLocale) Exceptions(count int) string {
+	if count == 1 {
+		return "excepto 1 fecha"
+	}
+	return fmt.Sprintf("excepto %d fechas", count)
+}
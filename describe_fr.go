@@ -0,0 +1,151 @@
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+var French Locale = frenchLocale{}
+
+type frenchLocale struct{}
+
+var frenchWeekdays = []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"}
+
+var frenchMonths = []string{
+	"", "janvier", "février", "mars", "avril", "mai", "juin",
+	"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+}
+
+var frenchUnits = map[FrequencyValue]string{
+	YEARLY: "année", MONTHLY: "mois", WEEKLY: "semaine", DAILY: "jour",
+	HOURLY: "heure", MINUTELY: "minute", SECONDLY: "seconde",
+}
+
+// Every n periods, the article agrees with the gender of the unit.
+var frenchIntervals = map[FrequencyValue]string{
+	YEARLY: "tous les %d ans", MONTHLY: "tous les %d mois", WEEKLY: "toutes les %d semaines",
+	DAILY: "tous les %d jours", HOURLY: "toutes les %d heures", MINUTELY: "toutes les %d minutes",
+	SECONDLY: "toutes les %d secondes",
+}
+
+// frenchNth writes a position with its noun and article, "le premier
+// lundi", "la dernière semaine" or "l'avant-dernier jour".
+func frenchNth(n int, noun string, feminine bool) string {
+	article := "le "
+	if feminine {
+		article = "la "
+	}
+
+	var word string
+	switch {
+	case n == 1 && feminine:
+		word = "première"
+	case n == 1:
+		word = "premier"
+	case n > 1:
+		word = fmt.Sprintf("%de", n)
+	case n == -1 && feminine:
+		word = "dernière"
+	case n == -1:
+		word = "dernier"
+	case n == -2 && feminine:
+		return "l'avant-dernière " + noun
+	case n == -2:
+		return "l'avant-dernier " + noun
+	default:
+		return fmt.Sprintf("%s%de %s en partant de la fin", article, -n, noun)
+	}
+
+	return article + word + " " + noun
+}
+
+func (frenchLocale) Frequency(freq FrequencyValue, interval int) string {
+	if interval == 1 {
+		return "chaque " + frenchUnits[freq]
+	}
+	return fmt.Sprintf(frenchIntervals[freq], interval)
+}
+
+func (frenchLocale) Months(months []time.Month) string {
+	var names []string
+	for _, m := range months {
+		names = append(names, frenchMonths[m])
+	}
+	return "en " + joinWords(names, "et")
+}
+
+func (frenchLocale) Weekdays(days []ForDay) string {
+	var names []string
+	for _, fd := range days {
+		if fd.Offset == 0 {
+			names = append(names, "le "+frenchWeekdays[fd.Weekday])
+		} else {
+			names = append(names, frenchNth(fd.Offset, frenchWeekdays[fd.Weekday], false))
+		}
+	}
+	return joinWords(names, "et")
+}
+
+func (frenchLocale) Numbers(part string, values []int, freq FrequencyValue) string {
+	switch part {
+	case "BYWEEKNO":
+		return joinWords(mapInts(values, func(n int) string {
+			if n > 0 {
+				return fmt.Sprintf("la semaine %d", n)
+			}
+			return frenchNth(n, "semaine", true)
+		}), "et") + " de l'année"
+	case "BYYEARDAY":
+		return joinWords(mapInts(values, func(n int) string {
+			return frenchNth(n, "jour", false)
+		}), "et") + " de l'année"
+	case "BYMONTHDAY":
+		// Days of the month are cardinal, except for the first.
+		return joinWords(mapInts(values, func(n int) string {
+			switch {
+			case n == 1:
+				return "le 1er"
+			case n > 1:
+				return fmt.Sprintf("le %d", n)
+			}
+			return frenchNth(n, "jour", false)
+		}), "et") + " du mois"
+	case "BYHOUR":
+		return "à " + joinWords(mapInts(values, func(n int) string { return fmt.Sprintf("%d h", n) }), "et")
+	case "BYMINUTE":
+		return "à la minute " + joinWords(mapInts(values, plainNumber), "et")
+	case "BYSECOND":
+		return "à la seconde " + joinWords(mapInts(values, plainNumber), "et")
+	case "BYSETPOS":
+		return "seulement " + joinWords(mapInts(values, func(n int) string {
+			return frenchNth(n, "occurrence", true)
+		}), "et") + " de chaque " + frenchUnits[freq]
+	}
+	return ""
+}
+
+func (frenchLocale) WeekStart(day time.Weekday) string {
+	return "avec des semaines commençant le " + frenchWeekdays[day]
+}
+
+func (frenchLocale) Count(count int) string {
+	if count == 1 {
+		return "une fois"
+	}
+	return fmt.Sprintf("%d fois", count)
+}
+
+func (frenchLocale) Until(until time.Time) string {
+	day := fmt.Sprintf("%d", until.Day())
+	if until.Day() == 1 {
+		day = "1er"
+	}
+	return fmt.Sprintf("jusqu'au %s %s %d", day, frenchMonths[until.Month()], until.Year())
+}
+
+func (frenchLocale) Exceptions(count int) string {
+	if count == 1 {
+		return "sauf 1 date"
+	}
+	return fmt.Sprintf("sauf %d dates", count)
+}
@@ -0,0 +1,107 @@
+package rrule
+
+import (
+	"testing"
+)
+
+func Test_Describe(t *testing.T) {
+	var cases = []struct {
+		rule     string
+		expected map[string]string
+	}{
+		{
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10",
+			map[string]string{
+				"en": "Every 2 weeks on Monday and Wednesday, 10 times",
+				"de": "Alle 2 Wochen am Montag und Mittwoch, 10 Mal",
+				"fr": "Toutes les 2 semaines le lundi et le mercredi, 10 fois",
+				"es": "Cada 2 semanas el lunes y el miércoles, 10 veces",
+			},
+		},
+		{
+			"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			map[string]string{
+				"en": "Every month on Monday, Tuesday, Wednesday, Thursday and Friday only the last occurrence in each month",
+				"de": "Monatlich am Montag, Dienstag, Mittwoch, Donnerstag und Freitag nur am letzten Termin jedes Monats",
+				"fr": "Chaque mois le lundi, le mardi, le mercredi, le jeudi et le vendredi seulement la dernière occurrence de chaque mois",
+				"es": "Cada mes el lunes, el martes, el miércoles, el jueves y el viernes solo la última repetición de cada mes",
+			},
+		},
+		{
+			"RRULE:FREQ=YEARLY;BYMONTH=1,3;BYDAY=1SU,-2FR;UNTIL=19971224T000000",
+			map[string]string{
+				"en": "Every year in January and March on the first Sunday and the second to last Friday, until December 24, 1997",
+				"de": "Jährlich im Januar und März am ersten Sonntag und vorletzten Freitag, bis zum 24. Dezember 1997",
+				"fr": "Chaque année en janvier et mars le premier dimanche et l'avant-dernier vendredi, jusqu'au 24 décembre 1997",
+				"es": "Cada año en enero y marzo el primer domingo y el penúltimo viernes, hasta el 24 de diciembre de 1997",
+			},
+		},
+		{
+			"RRULE:FREQ=MONTHLY;BYMONTHDAY=1,-1",
+			map[string]string{
+				"en": "Every month on the 1st and last days of the month",
+				"de": "Monatlich am 1. und letzten Tag des Monats",
+				"fr": "Chaque mois le 1er et le dernier jour du mois",
+				"es": "Cada mes el día 1 y el último día del mes",
+			},
+		},
+		{
+			"RRULE:FREQ=YEARLY;BYWEEKNO=20,-1;BYYEARDAY=100;WKST=SU",
+			map[string]string{
+				"en": "Every year in the 20th and last weeks of the year on the 100th day of the year with weeks starting on Sunday",
+				"de": "Jährlich in der 20. und letzten Woche des Jahres am 100. Tag des Jahres mit Wochenbeginn am Sonntag",
+				"fr": "Chaque année la semaine 20 et la dernière semaine de l'année le 100e jour de l'année avec des semaines commençant le dimanche",
+				"es": "Cada año la semana 20 y la última semana del año el día 100 del año con semanas que empiezan el domingo",
+			},
+		},
+		{
+			"RRULE:FREQ=DAILY;BYHOUR=9,17;BYMINUTE=30;COUNT=1",
+			map[string]string{
+				"en": "Every day at hours 9 and 17 at minute 30, once",
+				"de": "Täglich um 9 und 17 Uhr zur Minute 30, einmal",
+				"fr": "Chaque jour à 9 h et 17 h à la minute 30, une fois",
+				"es": "Cada día a las 9 y 17 h en el minuto 30, una vez",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + c.rule)
+
+		for language, expected := range c.expected {
+			locale, ok := LookupLocale(language)
+			if !ok {
+				t.Fatal("Missing locale", language)
+			}
+
+			if result := rule.Describe(locale); result != expected {
+				t.Log("Unexpected description", language, c.rule)
+				t.Log(result)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func Test_Describe_Exceptions(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=UTC:19970902T090000Z\n" +
+			"EXDATE;TZID=UTC:19970903T090000Z,19970904T090000Z\n" +
+			"RRULE:FREQ=HOURLY;INTERVAL=3",
+	)
+
+	var expected = map[string]string{
+		"en": "Every 3 hours, except on 2 dates",
+		"de": "Alle 3 Stunden, außer an 2 Terminen",
+		"fr": "Toutes les 3 heures, sauf 2 dates",
+		"es": "Cada 3 horas, excepto 2 fechas",
+	}
+
+	for language, value := range expected {
+		locale, _ := LookupLocale(language)
+		if result := rule.Describe(locale); result != value {
+			t.Log("Unexpected description", language, result)
+			t.Fail()
+		}
+	}
+}
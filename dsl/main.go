@@ -21,7 +21,7 @@ func main() {
 		panic(err)
 	}
 
-	rr.DtStart = last_time_it_happened
+	rr = rr.WithDtStart(last_time_it_happened)
 
 	fmt.Println(rr)
 
@@ -0,0 +1,145 @@
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS EventBridge schedules are one of
+//
+//	rate(value unit)
+//	cron(minute hour day-of-month month day-of-week year)
+//	at(yyyy-mm-ddThh:mm:ss)
+//
+// The cron form is Quartz without the seconds field, so it shares the
+// Quartz parser and formatter.
+
+// ParseEventBridge converts an EventBridge schedule expression into a
+// RuleSet. Rates start at from, cron expressions on the day of from, and
+// at() is read in the location of from.
+func ParseEventBridge(expr string, from time.Time) (RuleSet, error) {
+	expr = strings.TrimSpace(expr)
+
+	open := strings.Index(expr, "(")
+	if open < 0 || !strings.HasSuffix(expr, ")") {
+		return nil, BadFormatError(expr)
+	}
+
+	kind, body := expr[:open], strings.TrimSpace(expr[open+1:len(expr)-1])
+
+	switch kind {
+	case "rate":
+		parts := strings.Fields(body)
+		if len(parts) != 2 {
+			return nil, BadFormatError(body)
+		}
+
+		value, err := strconv.Atoi(parts[0])
+		if err != nil || value < 1 {
+			return nil, BadFormatError(body)
+		}
+
+		rr := &RecurringRule{
+			DtStart:       from,
+			Interval:      value,
+			WorkWeekStart: time.Monday,
+		}
+
+		switch strings.TrimSuffix(parts[1], "s") {
+		case "minute":
+			rr.Frequency = MINUTELY
+		case "hour":
+			rr.Frequency = HOURLY
+		case "day":
+			rr.Frequency = DAILY
+		default:
+			return nil, BadFormatError(parts[1])
+		}
+
+		return RuleSet{rr}, nil
+	case "cron":
+		fields := strings.Fields(body)
+		if len(fields) != 6 {
+			return nil, BadFormatError(body)
+		}
+
+		return parseQuartzFields(append([]string{"0"}, fields...), from, "EventBridge")
+	case "at":
+		at, err := time.ParseInLocation("2006-01-02T15:04:05", body, from.Location())
+		if err != nil {
+			return nil, BadFormatError(body)
+		}
+
+		return RuleSet{&RecurringRule{
+			DtStart:       at,
+			Frequency:     DAILY,
+			Interval:      1,
+			Count:         1,
+			WorkWeekStart: time.Monday,
+		}}, nil
+	}
+
+	return nil, BadFormatError(expr)
+}
+
+func (rr *RecurringRule) hasByParts() bool {
+	return len(rr.BySecond) > 0 || len(rr.ByMinute) > 0 || len(rr.ByHour) > 0 ||
+		len(rr.ByDay) > 0 || len(rr.ByMonthDay) > 0 || len(rr.ByYearDay) > 0 ||
+		len(rr.ByWeekNo) > 0 || len(rr.ByMonth) > 0 || len(rr.BySetPos) > 0
+}
+
+// EventBridgeExpression converts the rule into an EventBridge schedule.
+// Rules without BYxxx parts or bounds become rate(), a single occurrence
+// becomes at() and everything else is written as cron().
+func (rr *RecurringRule) EventBridgeExpression() (string, error) {
+	ce := &ConversionError{Format: "EventBridge"}
+
+	interval := rr.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	simple := !rr.hasByParts() && len(rr.ExceptionsToRule) == 0
+
+	if simple && rr.Count == 1 {
+		return fmt.Sprintf("at(%s)", rr.DtStart.Format("2006-01-02T15:04:05")), nil
+	}
+
+	if simple && rr.Count == 0 && rr.Until.Equal(EmptyTime) {
+		var unit string
+
+		switch rr.Frequency {
+		case MINUTELY:
+			unit = "minute"
+		case HOURLY:
+			unit = "hour"
+		case DAILY:
+			unit = "day"
+		}
+
+		if unit != "" {
+			if interval > 1 {
+				unit += "s"
+			}
+			return fmt.Sprintf("rate(%d %s)", interval, unit), nil
+		}
+	}
+
+	fields := rr.quartzFields(ce)
+
+	if fields[0] != "0" {
+		ce.Add("occurrences must be on a whole minute")
+	}
+
+	if fields[6] == "" {
+		fields[6] = "*"
+	}
+
+	if err := ce.Err(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("cron(%s)", strings.Join(fields[1:], " ")), nil
+}
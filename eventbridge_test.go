@@ -0,0 +1,115 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_EventBridge_Parse(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+	rules, err := ParseEventBridge("rate(5 minutes)", from)
+	if err != nil || rules[0].Frequency != MINUTELY || rules[0].Interval != 5 {
+		t.Log("Failed to parse rate", rules, err)
+		t.Fail()
+	}
+
+	rules, err = ParseEventBridge("cron(0 10 ? * MON-FRI *)", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := Parse("RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=10;BYDAY=MO,TU,WE,TH,FR")
+	expected.DtStart = time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	if len(rules) != 1 || !rules[0].Equal(expected) {
+		t.Log("Failed to parse cron", rules)
+		t.Fail()
+	}
+
+	rules, err = ParseEventBridge("cron(15 12 ? * 6L 2024)", from)
+	if err != nil || rules[0].ByDay[0] != (ForDay{Weekday: time.Friday, Offset: -1}) || rules[0].Until.Year() != 2024 {
+		t.Log("Failed to parse cron with L", rules, err)
+		t.Fail()
+	}
+
+	rules, err = ParseEventBridge("at(2024-03-01T13:00:00)", from)
+	var event time.Time
+	iter := rules.Iterator()
+	if err != nil || !iter.Step(&event) || iter.Step(&event) {
+		t.Log("at() should have a single occurrence", rules, err)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"rate(5 weeks)", "rate(0 minutes)", "cron(0 10 * * ?)", "cron(0 10 * * * *)", "every(5)"} {
+		if _, err := ParseEventBridge(bad, from); err == nil {
+			t.Log("Expected error for", bad)
+			t.Fail()
+		}
+	}
+}
+
+func Test_EventBridge_ToString(t *testing.T) {
+	var cases = map[string]string{
+		"RRULE:FREQ=MINUTELY;INTERVAL=5":                      "rate(5 minutes)",
+		"RRULE:FREQ=HOURLY":                                   "rate(1 hour)",
+		"RRULE:FREQ=DAILY;COUNT=1":                            "at(1997-09-02T09:00:00)",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR":              "cron(0 9 ? * 2-6 *)",
+		"RRULE:FREQ=WEEKLY;BYDAY=SU,MO,TU,WE,TH,FR,SA":        "cron(0 9 * * ? *)",
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1": "cron(0 9 LW * ? *)",
+		"RRULE:FREQ=MONTHLY;COUNT=1;BYDAY=1MO":                "",
+		"RRULE:FREQ=MONTHLY;BYDAY=1MO;UNTIL=19991231T235959Z": "cron(0 9 ? * 2#1 1997-1999)",
+	}
+
+	for value, expected := range cases {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		result, err := rule.EventBridgeExpression()
+		if expected == "" {
+			if err == nil {
+				t.Log("Expected error for", value, result)
+				t.Fail()
+			}
+			continue
+		}
+
+		if err != nil || result != expected {
+			t.Log("Failed to convert", value, result, err)
+			t.Fail()
+		}
+	}
+
+	// Expressions are parsed back to the same occurrences, with ? in one of
+	// the day fields.
+	for _, value := range []string{"RRULE:FREQ=DAILY;UNTIL=19991231T235959Z", "RRULE:FREQ=WEEKLY;BYDAY=SU,MO,TU,WE,TH,FR,SA"} {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		expr, _ := rule.EventBridgeExpression()
+		rules, err := ParseEventBridge(expr, rule.DtStart)
+		if err != nil {
+			t.Log("Failed to parse", expr, "from", value, err)
+			t.Fail()
+			continue
+		}
+
+		sameOccurrences(t, rule.Iterator().Limit(50), rules.Iterator().Limit(50), value, expr)
+	}
+
+	var unsupported = []string{
+		"RRULE:FREQ=DAILY;BYSECOND=30",
+		"RRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=2",
+		"RRULE:FREQ=DAILY;UNTIL=19991130T000000Z",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		if _, err := rule.EventBridgeExpression(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
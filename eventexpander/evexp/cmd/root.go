@@ -146,15 +146,13 @@ var RootCmd = &cobra.Command{
 				log.Panic(tzErr)
 			}
 
-			var startParseErr error
-
-			rule.DtStart, startParseErr = time.Parse(time.RFC3339, event.Start.DateTime)
+			dtStart, startParseErr := time.Parse(time.RFC3339, event.Start.DateTime)
 
 			if startParseErr != nil && exitOnFail {
 				log.Panic(startParseErr)
 			}
 
-			rule.DtStart = rule.DtStart.In(timeZone)
+			rule = rule.WithDtStart(dtStart.In(timeZone))
 
 			iter := rule.Iterator().
 				After(allowAfter).
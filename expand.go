@@ -0,0 +1,767 @@
+package rrule
+
+import (
+	"math"
+	"time"
+)
+
+// Occurrences are found a period at a time: a year for FREQ=YEARLY, a month
+// for MONTHLY, down to a single second for SECONDLY. Following the table
+// in RFC 5545 section 3.3.10, each BYxxx part either expands the period
+// (BYMONTH=1,2 in a year is every day of January and February) or limits
+// it (BYMONTH=1 in a day keeps the day only in January). The days of a
+// period are generated from the most specific expanding part (month days,
+// then year days, then weekdays), checked against the others and then
+// expanded to times. BYSETPOS picks from the sorted result.
+//
+// Days are counted from 1970-01-01 so the calendar arithmetic doesn't need
+// a time.Time for every day.
+
+// expansion is a rule prepared for finding occurrences, its lists are
+// sorted and the values RFC 5545 takes from DTSTART are filled in.
+type expansion struct {
+	freq     FrequencyValue
+	interval int
+	start    time.Time
+	loc      *time.Location
+	wkst     time.Weekday
+
+	// The first period, as a year and month or as a day.
+	startYear  int
+	startMonth int
+	startDay   int
+
+	months     [13]bool
+	hasMonths  bool
+	monthDays  []int
+	yearDays   []int
+	weekNos    []int
+	weekdays   [7]bool
+	nthDays    []ForDay
+	hasDays    bool
+	nthInMonth bool
+	limitsDays bool
+
+	// exactDays is set when candidateDays only lists matching days, so
+	// they don't have to go through matchDay.
+	exactDays bool
+
+	hours   []int
+	minutes []int
+	seconds []int
+	setPos  []int
+
+	// Buffers reused from one period to the next.
+	candidates []int
+	times      []time.Time
+	picked     []time.Time
+
+	// The offset of loc between zoneStart and zoneEnd, in Unix seconds,
+	// see at.
+	zoneOffset int64
+	zoneStart  int64
+	zoneEnd    int64
+}
+
+func newExpansion(rr *RecurringRule) *expansion {
+	ex := &expansion{
+		freq:     rr.Frequency,
+		interval: rr.Interval,
+		start:    rr.DtStart,
+		loc:      rr.DtStart.Location(),
+		wkst:     rr.WorkWeekStart,
+	}
+	if ex.interval < 1 {
+		ex.interval = 1
+	}
+
+	start := rr.DtStart
+	ex.startYear, ex.startMonth = start.Year(), int(start.Month())
+	ex.startDay = daysFromCivil(start.Year(), int(start.Month()), start.Day())
+
+	for _, m := range rr.ByMonth {
+		if m >= 1 && m <= 12 {
+			ex.months[m] = true
+			ex.hasMonths = true
+		}
+	}
+	ex.monthDays = sortedInts(rr.ByMonthDay)
+	ex.yearDays = sortedInts(rr.ByYearDay)
+	ex.weekNos = sortedInts(rr.ByWeekNo)
+	ex.setPos = sortedInts(rr.BySetPos)
+
+	// Offsets only mean something within a month or a year.
+	ex.nthInMonth = rr.Frequency == MONTHLY || (rr.Frequency == YEARLY && len(rr.ByMonth) > 0)
+	for _, fd := range rr.ByDay {
+		if fd.Offset == 0 || rr.Frequency > MONTHLY {
+			ex.weekdays[fd.Weekday] = true
+		} else {
+			ex.nthDays = append(ex.nthDays, fd)
+		}
+	}
+	ex.hasDays = len(rr.ByDay) > 0
+
+	// Without any day parts the day comes from DTSTART.
+	if len(rr.ByMonthDay) == 0 && len(rr.ByYearDay) == 0 && len(rr.ByDay) == 0 {
+		switch {
+		case rr.Frequency == YEARLY && len(rr.ByWeekNo) > 0:
+			ex.weekdays[start.Weekday()] = true
+			ex.hasDays = true
+		case rr.Frequency == YEARLY:
+			if !ex.hasMonths {
+				ex.months[start.Month()] = true
+				ex.hasMonths = true
+			}
+			ex.monthDays = []int{start.Day()}
+		case rr.Frequency == MONTHLY:
+			ex.monthDays = []int{start.Day()}
+		case rr.Frequency == WEEKLY:
+			ex.weekdays[start.Weekday()] = true
+			ex.hasDays = true
+		}
+	}
+
+	ex.limitsDays = ex.hasMonths || ex.hasDays || len(ex.monthDays) > 0 || len(ex.yearDays) > 0 || len(ex.weekNos) > 0
+
+	// Days worked out from a single day part match, as long as BYMONTH
+	// is applied to them as well.
+	switch {
+	case len(ex.weekNos) > 0:
+		ex.exactDays = false
+	case len(ex.monthDays) > 0:
+		ex.exactDays = len(ex.yearDays) == 0 && !ex.hasDays
+	case len(ex.yearDays) > 0:
+		ex.exactDays = !ex.hasMonths && !ex.hasDays
+	default:
+		ex.exactDays = true
+	}
+
+	// The same goes for the time of day, for periods longer than the part.
+	ex.hours = sortedInts(rr.ByHour)
+	if len(ex.hours) == 0 && rr.Frequency <= DAILY {
+		ex.hours = []int{start.Hour()}
+	}
+	ex.minutes = sortedInts(rr.ByMinute)
+	if len(ex.minutes) == 0 && rr.Frequency <= HOURLY {
+		ex.minutes = []int{start.Minute()}
+	}
+	ex.seconds = sortedInts(rr.BySecond)
+	if len(ex.seconds) == 0 && rr.Frequency <= MINUTELY {
+		ex.seconds = []int{start.Second()}
+	}
+
+	return ex
+}
+
+// period returns the occurrences in the nth period after the one DTSTART
+// is in, sorted and without duplicates. Some may be before DTSTART. The
+// slice is reused by the next call.
+func (ex *expansion) period(n int) []time.Time {
+	ex.times = ex.times[:0]
+
+	switch ex.freq {
+	case YEARLY:
+		year := ex.startYear + n*ex.interval
+		ex.addDays(daysFromCivil(year, 1, 1), daysFromCivil(year+1, 1, 1))
+	case MONTHLY:
+		month := ex.startYear*12 + ex.startMonth - 1 + n*ex.interval
+		year := floorDiv(month, 12)
+		first := daysFromCivil(year, month-year*12+1, 1)
+		ex.addDays(first, first+daysInMonth(year, month-year*12+1))
+	case WEEKLY:
+		first := ex.startDay - (weekdayOf(ex.startDay)-int(ex.wkst)+7)%7 + 7*n*ex.interval
+		ex.addDays(first, first+7)
+	case DAILY:
+		day := ex.startDay + n*ex.interval
+		ex.addDays(day, day+1)
+	default:
+		ex.addInstant(n)
+	}
+
+	ex.pickSetPos()
+	return ex.times
+}
+
+// periodOf returns the period t is in, or the first one for times before
+// it.
+func (ex *expansion) periodOf(t time.Time) int {
+	var n int
+
+	switch ex.freq {
+	case YEARLY, MONTHLY, WEEKLY, DAILY:
+		y, m, d := t.In(ex.loc).Date()
+		day := daysFromCivil(y, int(m), d)
+
+		switch ex.freq {
+		case YEARLY:
+			n = floorDiv(y-ex.startYear, ex.interval)
+		case MONTHLY:
+			n = floorDiv(y*12+int(m)-ex.startYear*12-ex.startMonth, ex.interval)
+		case WEEKLY:
+			first := ex.startDay - (weekdayOf(ex.startDay)-int(ex.wkst)+7)%7
+			n = floorDiv(day-first, 7*ex.interval)
+		default:
+			n = floorDiv(day-ex.startDay, ex.interval)
+		}
+	default:
+		// BYMINUTE and BYSECOND can put an occurrence of a period
+		// before its start, so start a period early.
+		n = int(t.Sub(ex.start)/ex.step()) - 1
+	}
+
+	return max(n, 0)
+}
+
+// next returns the period after the nth that can have occurrences. The
+// rest of a day that doesn't match, and the hours and minutes up to the
+// next ones that do, are skipped together.
+func (ex *expansion) next(n int) int {
+	if ex.freq <= DAILY {
+		return n + 1
+	}
+
+	step := ex.step()
+	t := ex.start.Add(step * time.Duration(n))
+	y, mo, d := t.Date()
+	h, mi, _ := t.Clock()
+	day := daysFromCivil(y, int(mo), d)
+
+	var end time.Time
+	switch {
+	case !ex.matchDay(day):
+		end = ex.at(day+1, 0, 0, 0)
+	case ex.freq >= MINUTELY && !containsInt(ex.hours, h):
+		end = ex.at(day, nextInt(ex.hours, h, 24), 0, 0)
+	case ex.freq >= MINUTELY && !containsInt(ex.minutes, mi):
+		end = ex.at(day, h, nextInt(ex.minutes, mi, 60), 0)
+	default:
+		return n + 1
+	}
+
+	return max(n+int((end.Sub(t)+step-1)/step), n+1)
+}
+
+// prev returns the period before the nth that can have occurrences, the
+// reverse of next.
+func (ex *expansion) prev(n int) int {
+	if ex.freq <= DAILY {
+		return n - 1
+	}
+
+	step := ex.step()
+	t := ex.start.Add(step * time.Duration(n))
+	y, mo, d := t.Date()
+	h, mi, _ := t.Clock()
+	day := daysFromCivil(y, int(mo), d)
+
+	var start time.Time
+	switch {
+	case !ex.matchDay(day):
+		start = ex.at(day, 0, 0, 0)
+	case ex.freq >= MINUTELY && !containsInt(ex.hours, h):
+		start = ex.at(day, prevInt(ex.hours, h, -1)+1, 0, 0)
+	case ex.freq >= MINUTELY && !containsInt(ex.minutes, mi):
+		start = ex.at(day, h, prevInt(ex.minutes, mi, -1)+1, 0)
+	default:
+		return n - 1
+	}
+
+	return min(n-int(t.Sub(start)/step)-1, n-1)
+}
+
+// step is the time between periods for HOURLY, MINUTELY and SECONDLY.
+func (ex *expansion) step() time.Duration {
+	switch ex.freq {
+	case HOURLY:
+		return time.Hour * time.Duration(ex.interval)
+	case MINUTELY:
+		return time.Minute * time.Duration(ex.interval)
+	}
+	return time.Second * time.Duration(ex.interval)
+}
+
+// addDays adds the occurrences on the days in [first, end) that match the
+// rule, expanded to the times of day.
+func (ex *expansion) addDays(first, end int) {
+	ex.candidates = ex.candidates[:0]
+	ex.candidateDays(first, end)
+	ex.candidates = sortUniqueInts(ex.candidates)
+
+	for _, day := range ex.candidates {
+		if !ex.exactDays && !ex.matchDay(day) {
+			continue
+		}
+
+		for _, h := range ex.hours {
+			for _, mi := range ex.minutes {
+				for _, s := range ex.seconds {
+					ex.times = append(ex.times, ex.at(day, h, mi, s))
+				}
+			}
+		}
+	}
+
+	// Times skipped or repeated by a change to daylight saving time can
+	// land out of order or on top of each other.
+	ex.times = sortUnique(ex.times)
+}
+
+// candidateDays lists the days in [first, end) that might match. They're
+// worked out from one of the day parts, BYMONTHDAY=15 is a single day of
+// each month and BYDAY=MO every seventh day, so the days in between aren't
+// looked at. matchDay checks them against the other parts.
+func (ex *expansion) candidateDays(first, end int) {
+	switch {
+	case len(ex.weekNos) > 0:
+		// The weeks of the years around can start or end in this one.
+		y, _, _ := civilFromDays(first)
+		last, _, _ := civilFromDays(end - 1)
+		for y -= 1; y <= last+1; y += 1 {
+			start := firstWeek(y, ex.wkst)
+			weeks := (firstWeek(y+1, ex.wkst) - start) / 7
+			for _, v := range ex.weekNos {
+				if v < 0 {
+					v += weeks + 1
+				}
+				for day := start + 7*(v-1); v >= 1 && v <= weeks && day < start+7*v; day += 1 {
+					ex.addCandidate(day, first, end)
+				}
+			}
+		}
+
+	case len(ex.monthDays) > 0:
+		ex.eachMonth(first, end, func(start, length int) {
+			for _, v := range ex.monthDays {
+				if v < 0 {
+					v += length + 1
+				}
+				if v >= 1 && v <= length {
+					ex.addCandidate(start+v-1, first, end)
+				}
+			}
+		})
+
+	case len(ex.yearDays) > 0:
+		y, _, _ := civilFromDays(first)
+		for start := daysFromCivil(y, 1, 1); start < end; y += 1 {
+			length := 365 + IsLeapYear(y)
+			for _, v := range ex.yearDays {
+				if v < 0 {
+					v += length + 1
+				}
+				if v >= 1 && v <= length {
+					ex.addCandidate(start+v-1, first, end)
+				}
+			}
+			start += length
+		}
+
+	case ex.hasDays:
+		ex.eachMonth(first, end, func(start, length int) {
+			from, to := max(start, first), min(start+length, end)
+			for day, w := from, weekdayOf(from); day < to; day, w = day+1, (w+1)%7 {
+				if ex.weekdays[w] {
+					ex.candidates = append(ex.candidates, day)
+				}
+			}
+			if ex.nthInMonth {
+				ex.addNthDays(start, start+length, first, end)
+			}
+		})
+		if !ex.nthInMonth {
+			ex.addNthDays(first, end, first, end)
+		}
+
+	default:
+		ex.eachMonth(first, end, func(start, length int) {
+			for day := max(start, first); day < min(start+length, end); day += 1 {
+				ex.candidates = append(ex.candidates, day)
+			}
+		})
+	}
+}
+
+// eachMonth calls fn with the first day and the length of every month
+// overlapping [first, end), skipping those BYMONTH leaves out.
+func (ex *expansion) eachMonth(first, end int, fn func(start, length int)) {
+	y, m, d := civilFromDays(first)
+	for start := first - d + 1; start < end; {
+		length := daysInMonth(y, m)
+		if !ex.hasMonths || ex.months[m] {
+			fn(start, length)
+		}
+
+		start += length
+		if m += 1; m > 12 {
+			y, m = y+1, 1
+		}
+	}
+}
+
+// addNthDays adds the days of BYDAY with an offset, counted within
+// [start, end), a month or a year. Only the days in both that and [first,
+// last) are added.
+func (ex *expansion) addNthDays(start, end, first, last int) {
+	first, last = max(start, first), min(end, last)
+	for _, fd := range ex.nthDays {
+		w := int(fd.Weekday)
+		if fd.Offset > 0 {
+			ex.addCandidate(start+(w-weekdayOf(start)+7)%7+7*(fd.Offset-1), first, last)
+		} else {
+			ex.addCandidate(end-1-(weekdayOf(end-1)-w+7)%7+7*(fd.Offset+1), first, last)
+		}
+	}
+}
+
+func (ex *expansion) addCandidate(day, first, end int) {
+	if day >= first && day < end {
+		ex.candidates = append(ex.candidates, day)
+	}
+}
+
+// addInstant adds the occurrences in the nth hour, minute or second. These
+// periods are counted in elapsed time, so they're the same length across
+// daylight saving time changes.
+func (ex *expansion) addInstant(n int) {
+	t := ex.start.Add(ex.step() * time.Duration(n))
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+
+	if !ex.matchDay(daysFromCivil(y, int(mo), d)) {
+		return
+	}
+
+	// Parts for the period and longer limit it.
+	if !containsInt(ex.hours, h) ||
+		(ex.freq >= MINUTELY && !containsInt(ex.minutes, mi)) ||
+		(ex.freq == SECONDLY && !containsInt(ex.seconds, s)) {
+		return
+	}
+
+	switch ex.freq {
+	case HOURLY:
+		for _, minute := range ex.minutes {
+			for _, second := range ex.seconds {
+				offset := time.Duration(minute-mi)*time.Minute + time.Duration(second-s)*time.Second
+				ex.times = append(ex.times, t.Add(offset))
+			}
+		}
+	case MINUTELY:
+		for _, second := range ex.seconds {
+			ex.times = append(ex.times, t.Add(time.Duration(second-s)*time.Second))
+		}
+	default:
+		ex.times = append(ex.times, t)
+	}
+}
+
+// at returns the time of day on a day. Most days are far from a change to
+// daylight saving time, so the offset of the last zone is used when the
+// result is at least a day from either end of it. Otherwise the zone the
+// result would be in is tried, only times within a day of a change go
+// through localTime.
+func (ex *expansion) at(day, h, mi, s int) time.Time {
+	wall := int64(day)*86400 + int64(h*3600+mi*60+s)
+
+	for i := 0; i < 2 && s < 60; i += 1 {
+		if unix := wall - ex.zoneOffset; unix >= ex.zoneStart && unix < ex.zoneEnd {
+			return time.Unix(unix, 0).In(ex.loc)
+		}
+		ex.setZone(time.Unix(wall-ex.zoneOffset, 0).In(ex.loc))
+	}
+	if unix := wall - ex.zoneOffset; unix >= ex.zoneStart && unix < ex.zoneEnd && s < 60 {
+		return time.Unix(unix, 0).In(ex.loc)
+	}
+
+	y, m, d := civilFromDays(day)
+	t := localTime(y, m, d, h, mi, s, ex.loc)
+	ex.setZone(t)
+	return t
+}
+
+// setZone keeps the offset of the zone t is in for at, with its bounds
+// moved in by a day.
+func (ex *expansion) setZone(t time.Time) {
+	_, offset := t.Zone()
+	start, end := t.ZoneBounds()
+
+	ex.zoneOffset = int64(offset)
+	ex.zoneStart, ex.zoneEnd = math.MinInt64, math.MaxInt64
+	if !start.Equal(EmptyTime) {
+		ex.zoneStart = start.Unix() + 86400
+	}
+	if !end.Equal(EmptyTime) {
+		ex.zoneEnd = end.Unix() - 86400
+	}
+}
+
+// localTime is time.Date, except for times skipped by a change to daylight
+// saving time. RFC 5545 reads them with the offset before the change, so
+// 02:30 on the morning clocks go from 02:00 to 03:00 is 03:30, time.Date
+// moves them either way. Repeated times are the first, as with time.Date.
+func localTime(y, m, d, h, mi, s int, loc *time.Location) time.Time {
+	t := time.Date(y, time.Month(m), d, h, mi, s, 0, loc)
+
+	wall := time.Date(y, time.Month(m), d, h, mi, s, 0, time.UTC)
+	moved := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	if moved.Equal(wall) || s > 59 {
+		return t
+	}
+
+	// t is on one side of the change, find the offset before it.
+	_, offset := t.Zone()
+	if moved.After(wall) {
+		start, _ := t.ZoneBounds()
+		_, offset = start.Add(-time.Second).Zone()
+	}
+	return wall.Add(-time.Duration(offset) * time.Second).In(loc)
+}
+
+// containsInt is true for an empty list, a part that isn't set doesn't
+// limit anything.
+func containsInt(values []int, value int) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// nextInt is the first of the sorted values after value, or otherwise,
+// which is also the bound for values out of range.
+func nextInt(values []int, value, otherwise int) int {
+	for _, v := range values {
+		if v > value && v < otherwise {
+			return v
+		}
+	}
+	return otherwise
+}
+
+// prevInt is the last of the sorted values before value, or otherwise, the
+// lower bound.
+func prevInt(values []int, value, otherwise int) int {
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] < value && values[i] > otherwise {
+			return values[i]
+		}
+	}
+	return otherwise
+}
+
+func (ex *expansion) matchDay(day int) bool {
+	if !ex.limitsDays {
+		return true
+	}
+	y, m, d := civilFromDays(day)
+
+	if ex.hasMonths && !ex.months[m] {
+		return false
+	}
+
+	if len(ex.weekNos) > 0 {
+		week, weeks := weekNumber(day, ex.wkst)
+		if !matchPosition(ex.weekNos, week, weeks) {
+			return false
+		}
+	}
+
+	yearDay := day - daysFromCivil(y, 1, 1) + 1
+	yearLength := 365 + IsLeapYear(y)
+	if len(ex.yearDays) > 0 && !matchPosition(ex.yearDays, yearDay, yearLength) {
+		return false
+	}
+
+	monthLength := daysInMonth(y, m)
+	if len(ex.monthDays) > 0 && !matchPosition(ex.monthDays, d, monthLength) {
+		return false
+	}
+
+	if !ex.hasDays {
+		return true
+	}
+
+	weekday := time.Weekday(weekdayOf(day))
+	if ex.weekdays[weekday] {
+		return true
+	}
+
+	// The nth weekday of the month or the year, counted from either end.
+	position, length := yearDay, yearLength
+	if ex.nthInMonth {
+		position, length = d, monthLength
+	}
+	nth := (position-1)/7 + 1
+	nthLast := -((length-position)/7 + 1)
+
+	for _, fd := range ex.nthDays {
+		if fd.Weekday == weekday && (fd.Offset == nth || fd.Offset == nthLast) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPosition is true when position, of length, is in values, which
+// count from the end when they're negative.
+func matchPosition(values []int, position, length int) bool {
+	for _, v := range values {
+		if v == position || v == position-length-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// pickSetPos keeps the occurrences BYSETPOS picks.
+func (ex *expansion) pickSetPos() {
+	if len(ex.setPos) == 0 || len(ex.times) == 0 {
+		return
+	}
+
+	picked := ex.picked[:0]
+	for _, pos := range ex.setPos {
+		index := pos - 1
+		if pos < 0 {
+			index = len(ex.times) + pos
+		}
+		if index >= 0 && index < len(ex.times) {
+			picked = append(picked, ex.times[index])
+		}
+	}
+
+	ex.picked, ex.times = ex.times, sortUnique(picked)
+}
+
+// sortUnique sorts in place, the times are nearly always in order already.
+func sortUnique(times []time.Time) []time.Time {
+	for i := 1; i < len(times); i += 1 {
+		for j := i; j > 0 && times[j].Before(times[j-1]); j -= 1 {
+			times[j], times[j-1] = times[j-1], times[j]
+		}
+	}
+
+	results := times[:0]
+	for _, t := range times {
+		if len(results) == 0 || !t.Equal(results[len(results)-1]) {
+			results = append(results, t)
+		}
+	}
+	return results
+}
+
+func sortUniqueInts(values []int) []int {
+	for i := 1; i < len(values); i += 1 {
+		for j := i; j > 0 && values[j] < values[j-1]; j -= 1 {
+			values[j], values[j-1] = values[j-1], values[j]
+		}
+	}
+
+	results := values[:0]
+	for _, v := range values {
+		if len(results) == 0 || v != results[len(results)-1] {
+			results = append(results, v)
+		}
+	}
+	return results
+}
+
+func sortedInts(values []int16) []int {
+	var results []int
+	for _, v := range sortedUnique(values) {
+		results = append(results, int(v))
+	}
+	return results
+}
+
+// weekNumber returns the week of the year day is in and how many weeks
+// that year has. Week 1 is the first week starting on wkst with at least
+// four days in the year, so the first and last days of a year can be in
+// the weeks of the years around it.
+func weekNumber(day int, wkst time.Weekday) (int, int) {
+	year, _, _ := civilFromDays(day)
+
+	first := firstWeek(year, wkst)
+	if day < first {
+		year -= 1
+		first = firstWeek(year, wkst)
+	} else if next := firstWeek(year+1, wkst); day >= next {
+		year += 1
+		first = next
+	}
+
+	weeks := (firstWeek(year+1, wkst) - first) / 7
+	return (day-first)/7 + 1, weeks
+}
+
+func firstWeek(year int, wkst time.Weekday) int {
+	jan1 := daysFromCivil(year, 1, 1)
+	back := (weekdayOf(jan1) - int(wkst) + 7) % 7
+
+	if back > 3 {
+		return jan1 - back + 7
+	}
+	return jan1 - back
+}
+
+func daysInMonth(year, month int) int {
+	switch month {
+	case 2:
+		return 28 + IsLeapYear(year)
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// weekdayOf returns the time.Weekday of a day, 1970-01-01 was a Thursday.
+func weekdayOf(day int) int {
+	return ((day+4)%7 + 7) % 7
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((b - 1 - a) / b)
+	}
+	return a / b
+}
+
+// daysFromCivil and civilFromDays convert between dates and days since
+// 1970-01-01 in the proleptic Gregorian calendar, see
+// http://howardhinnant.github.io/date_algorithms.html
+func daysFromCivil(y, m, d int) int {
+	if m <= 2 {
+		y -= 1
+	}
+	era := floorDiv(y, 400)
+	yearOfEra := y - era*400
+	dayOfYear := (153*((m+9)%12)+2)/5 + d - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+
+	return era*146097 + dayOfEra - 719468
+}
+
+func civilFromDays(days int) (int, int, int) {
+	days += 719468
+	era := floorDiv(days, 146097)
+	dayOfEra := days - era*146097
+	yearOfEra := (dayOfEra - dayOfEra/1460 + dayOfEra/36524 - dayOfEra/146096) / 365
+	dayOfYear := dayOfEra - (365*yearOfEra + yearOfEra/4 - yearOfEra/100)
+	mp := (5*dayOfYear + 2) / 153
+
+	d := dayOfYear - (153*mp+2)/5 + 1
+	m := mp + 3
+	if m > 12 {
+		m -= 12
+	}
+
+	y := yearOfEra + era*400
+	if m <= 2 {
+		y += 1
+	}
+	return y, m, d
+}
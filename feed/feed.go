@@ -0,0 +1,171 @@
+// Package feed serves recurring rules as an iCalendar feed that calendar
+// applications can subscribe to.
+//
+//	http.Handle("/team.ics", &feed.Handler{
+//		Name:     "Team",
+//		Events:   events,
+//		Modified: lastChanged,
+//	})
+//
+// Responses carry an ETag and Last-Modified, so polling clients get a 304
+// when nothing changed.
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/graham/rrule"
+)
+
+// Without a window, expanded feeds cover the next year.
+const defaultFuture = 365 * 24 * time.Hour
+
+// Handler serves a VCALENDAR with Events. It may be changed between
+// requests but not during one.
+type Handler struct {
+	// Name and Description are X-WR-CALNAME and X-WR-CALDESC, other
+	// calendar properties go in Properties.
+	Name        string
+	Description string
+	Properties  []rrule.ICalProperty
+
+	Events []*rrule.ICalEvent
+
+	// Expand writes every instance from Past before now to Future after
+	// it as its own VEVENT, for consumers that can't expand an RRULE.
+	// Otherwise each event is written once with its RRULE.
+	Expand bool
+	Past   time.Duration
+	Future time.Duration
+
+	// Modified is when the events last changed. It's Last-Modified and the
+	// DTSTAMP of events without a Stamp, and should be set so the feed is
+	// the same, and keeps its ETag, until the events change.
+	Modified time.Time
+
+	// Now is time.Now when nil.
+	Now func() time.Time
+}
+
+// ServeHTTP answers GET and HEAD requests, with a 304 when If-None-Match
+// or If-Modified-Since show the client has the feed already.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := h.WriteICal(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+
+	// An expanded feed moves with the window, so it changes without the
+	// events changing and only the ETag can tell.
+	modified := h.Modified
+	if h.Expand {
+		modified = rrule.EmptyTime
+	}
+
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body.Bytes()))
+}
+
+// WriteICal writes the feed as it's served.
+func (h *Handler) WriteICal(w io.Writer) error {
+	var properties []rrule.ICalProperty
+	if h.Name != "" {
+		properties = append(properties, rrule.ICalProperty{Name: "X-WR-CALNAME", Value: h.Name})
+	}
+	if h.Description != "" {
+		properties = append(properties, rrule.ICalProperty{Name: "X-WR-CALDESC", Value: h.Description})
+	}
+	properties = append(properties, h.Properties...)
+
+	now := time.Now()
+	if h.Now != nil {
+		now = h.Now()
+	}
+	future := h.Future
+	if future == 0 {
+		future = defaultFuture
+	}
+	after, before := now.Add(-h.Past), now.Add(future)
+
+	events := h.stamped()
+	iw := rrule.NewICalWriter(w, properties...)
+
+	for _, loc := range locations(events) {
+		from := after
+		for _, event := range events {
+			if event.Rule == nil || event.Rule.DtStart.Location().String() != loc.String() {
+				continue
+			}
+			if h.Expand && after.Add(-event.Length()).Before(from) {
+				from = after.Add(-event.Length())
+			}
+			if !h.Expand && event.Rule.DtStart.Before(from) {
+				from = event.Rule.DtStart
+			}
+		}
+		iw.Timezone(loc, from, before)
+	}
+
+	for _, event := range events {
+		var err error
+		if h.Expand {
+			err = iw.Expanded(event, after, before)
+		} else {
+			err = iw.Recurring(event)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return iw.Close()
+}
+
+// stamped gives events without a Stamp the time of the last change.
+func (h *Handler) stamped() []*rrule.ICalEvent {
+	var events []*rrule.ICalEvent
+	for _, event := range h.Events {
+		if event.Stamp.Equal(rrule.EmptyTime) && !h.Modified.Equal(rrule.EmptyTime) {
+			stamped := *event
+			stamped.Stamp = h.Modified
+			event = &stamped
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// locations returns the time zones of the events that need a VTIMEZONE,
+// in the order they're first used. UTC and Local times don't.
+func locations(events []*rrule.ICalEvent) []*time.Location {
+	var results []*time.Location
+	seen := map[string]bool{}
+
+	for _, event := range events {
+		if event.Rule == nil {
+			continue
+		}
+		loc := event.Rule.DtStart.Location()
+		if loc == time.UTC || loc == time.Local || seen[loc.String()] {
+			continue
+		}
+		seen[loc.String()] = true
+		results = append(results, loc)
+	}
+	return results
+}
@@ -0,0 +1,156 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graham/rrule"
+)
+
+func testHandler() *Handler {
+	standup, _ := rrule.Parse("DTSTART;TZID=America/New_York:20241021T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE")
+	review, _ := rrule.Parse("DTSTART;TZID=UTC:20241001T150000Z\nRRULE:FREQ=MONTHLY;COUNT=6")
+
+	return &Handler{
+		Name: "Team",
+		Events: []*rrule.ICalEvent{
+			{UID: "standup", Rule: standup, Duration: 15 * time.Minute,
+				Properties: []rrule.ICalProperty{{Name: "SUMMARY", Value: "Standup"}}},
+			{UID: "review", Rule: review, Duration: time.Hour},
+		},
+		Modified: time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+		Now:      func() time.Time { return time.Date(2024, time.October, 28, 12, 0, 0, 0, time.UTC) },
+	}
+}
+
+func get(h http.Handler, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/team.ics", nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func Test_Handler_Recurring(t *testing.T) {
+	w := get(testHandler(), nil)
+	body := w.Body.String()
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Log("Unexpected response", w.Code, w.Header())
+		t.Fail()
+	}
+
+	for _, line := range []string{
+		"X-WR-CALNAME:Team\r\n",
+		"TZID:America/New_York\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE\r\n",
+		"RRULE:FREQ=MONTHLY;COUNT=6\r\n",
+		"DTSTAMP:20241001T120000Z\r\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Log("Missing line", line)
+			t.Fail()
+		}
+	}
+
+	if strings.Count(body, "BEGIN:VEVENT") != 2 || strings.Count(body, "BEGIN:VTIMEZONE") != 1 {
+		t.Log("Unexpected components", body)
+		t.Fail()
+	}
+
+	if w.Header().Get("Last-Modified") != "Tue, 01 Oct 2024 12:00:00 GMT" || w.Header().Get("ETag") == "" {
+		t.Log("Unexpected validators", w.Header())
+		t.Fail()
+	}
+}
+
+func Test_Handler_Expanded(t *testing.T) {
+	h := testHandler()
+	h.Expand = true
+	h.Future = 10 * 24 * time.Hour
+
+	w := get(h, nil)
+	body := w.Body.String()
+
+	// Oct 28 and 30, Nov 4 and 6, and the review on Nov 1.
+	if strings.Count(body, "BEGIN:VEVENT") != 5 || strings.Contains(body, "RRULE:FREQ=WEEKLY") {
+		t.Log("Unexpected instances", body)
+		t.Fail()
+	}
+	if !strings.Contains(body, "RECURRENCE-ID:20241101T150000Z\r\n") {
+		t.Log("Missing review", body)
+		t.Fail()
+	}
+
+	// The window moved, the feed can't be judged by its date.
+	if w.Header().Get("Last-Modified") != "" {
+		t.Log("Expanded feeds have no Last-Modified", w.Header())
+		t.Fail()
+	}
+
+	etag := w.Header().Get("ETag")
+	h.Now = func() time.Time { return time.Date(2024, time.October, 29, 12, 0, 0, 0, time.UTC) }
+	if get(h, nil).Header().Get("ETag") == etag {
+		t.Log("ETag didn't change with the window")
+		t.Fail()
+	}
+}
+
+func Test_Handler_ExpandedOldStart(t *testing.T) {
+	// An on-call rotation that has run for years.
+	rotation, _ := rrule.Parse("DTSTART;TZID=Europe/London:20100104T090000\nRRULE:FREQ=HOURLY;INTERVAL=12")
+
+	h := testHandler()
+	h.Events = []*rrule.ICalEvent{{UID: "on-call", Rule: rotation, Duration: 12 * time.Hour}}
+	h.Expand = true
+	h.Future = 24 * time.Hour
+
+	w := get(h, nil)
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), "BEGIN:VEVENT") != 3 {
+		t.Log("Unexpected response", w.Code, w.Body.String())
+		t.Fail()
+	}
+}
+
+func Test_Handler_NotModified(t *testing.T) {
+	h := testHandler()
+	etag := get(h, nil).Header().Get("ETag")
+
+	w := get(h, http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Log("Expected 304 for a matching ETag", w.Code)
+		t.Fail()
+	}
+
+	w = get(h, http.Header{"If-Modified-Since": {"Wed, 02 Oct 2024 00:00:00 GMT"}})
+	if w.Code != http.StatusNotModified {
+		t.Log("Expected 304 for an unchanged feed", w.Code)
+		t.Fail()
+	}
+
+	// Events changed after the client's copy.
+	h.Events = h.Events[:1]
+	h.Modified = time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC)
+
+	if w = get(h, http.Header{"If-None-Match": {etag}}); w.Code != http.StatusOK {
+		t.Log("Expected 200 for a stale ETag", w.Code)
+		t.Fail()
+	}
+	if w = get(h, http.Header{"If-Modified-Since": {"Wed, 02 Oct 2024 00:00:00 GMT"}}); w.Code != http.StatusOK {
+		t.Log("Expected 200 for a changed feed", w.Code)
+		t.Fail()
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/team.ics", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Log("Expected 405 for POST", rec.Code)
+		t.Fail()
+	}
+}
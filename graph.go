@@ -0,0 +1,343 @@
+package rrule
+
+import (
+	"strings"
+	"time"
+)
+
+// Microsoft Graph (and Outlook) describe recurrences with a
+// patternedRecurrence, see
+// https://learn.microsoft.com/en-us/graph/api/resources/patternedrecurrence
+// The pattern only carries dates, the time of day comes from the start of
+// the event.
+
+// GraphDateFormat is the layout of startDate and endDate.
+const GraphDateFormat = "2006-01-02"
+
+// https://learn.microsoft.com/en-us/graph/api/resources/recurrencepattern
+type GraphRecurrencePattern struct {
+	Type           string   `json:"type"`
+	Interval       int      `json:"interval"`
+	Month          int      `json:"month,omitempty"`
+	DayOfMonth     int      `json:"dayOfMonth,omitempty"`
+	DaysOfWeek     []string `json:"daysOfWeek,omitempty"`
+	FirstDayOfWeek string   `json:"firstDayOfWeek,omitempty"`
+	Index          string   `json:"index,omitempty"`
+}
+
+// https://learn.microsoft.com/en-us/graph/api/resources/recurrencerange
+type GraphRecurrenceRange struct {
+	Type                string `json:"type"`
+	StartDate           string `json:"startDate"`
+	EndDate             string `json:"endDate,omitempty"`
+	RecurrenceTimeZone  string `json:"recurrenceTimeZone,omitempty"`
+	NumberOfOccurrences int    `json:"numberOfOccurrences,omitempty"`
+}
+
+type GraphPatternedRecurrence struct {
+	Pattern GraphRecurrencePattern `json:"pattern"`
+	Range   GraphRecurrenceRange   `json:"range"`
+}
+
+var graphIndexes = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "last": -1,
+}
+
+func graphIndexFromOffset(offset int) (string, bool) {
+	for name, value := range graphIndexes {
+		if value == offset {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func graphDayFromWeekday(d time.Weekday) string {
+	return strings.ToLower(d.String())
+}
+
+func weekdayFromGraphDay(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d += 1 {
+		if graphDayFromWeekday(d) == strings.ToLower(s) {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func graphRelativeInto(rr *RecurringRule, gp *GraphRecurrencePattern, ce *ConversionError) {
+	index := gp.Index
+	if index == "" {
+		index = "first"
+	}
+
+	offset, ok := graphIndexes[index]
+	if !ok {
+		ce.Add("invalid index %q", gp.Index)
+		return
+	}
+
+	days := graphDaysOfWeek(gp, ce)
+	if len(days) == 0 {
+		ce.Add("%s requires daysOfWeek", gp.Type)
+		return
+	}
+
+	relativeDaysInto(rr, days, offset)
+}
+
+func graphDaysOfWeek(gp *GraphRecurrencePattern, ce *ConversionError) []ForDay {
+	var days []ForDay
+	for _, name := range gp.DaysOfWeek {
+		wd, ok := weekdayFromGraphDay(name)
+		if !ok {
+			ce.Add("invalid daysOfWeek %q", name)
+			continue
+		}
+		days = append(days, ForDay{Weekday: wd})
+	}
+	return days
+}
+
+// RuleFromGraph converts a Graph patternedRecurrence into a RecurringRule.
+// start is the start of the event and provides the time of day, its
+// location is used unless the range has a recurrenceTimeZone. endDate is
+// inclusive, so Until is the end of that day.
+func RuleFromGraph(gr *GraphPatternedRecurrence, start time.Time) (*RecurringRule, error) {
+	ce := &ConversionError{Format: "Graph"}
+	gp := &gr.Pattern
+
+	rr := &RecurringRule{
+		Interval:      1,
+		WorkWeekStart: time.Sunday,
+	}
+
+	loc := start.Location()
+	if gr.Range.RecurrenceTimeZone != "" {
+		tz, err := time.LoadLocation(gr.Range.RecurrenceTimeZone)
+		if err != nil {
+			ce.Add("unknown recurrenceTimeZone %q", gr.Range.RecurrenceTimeZone)
+			return rr, ce
+		}
+		loc = tz
+	}
+
+	start = start.In(loc)
+	rr.DtStart = start
+
+	if gr.Range.StartDate != "" {
+		date, err := time.ParseInLocation(GraphDateFormat, gr.Range.StartDate, loc)
+		if err != nil {
+			ce.Add("invalid startDate %q", gr.Range.StartDate)
+		}
+		rr.DtStart = time.Date(
+			date.Year(), date.Month(), date.Day(),
+			start.Hour(), start.Minute(), start.Second(), 0, loc,
+		)
+	}
+
+	if gp.Interval > 0 {
+		rr.Interval = gp.Interval
+	}
+
+	if gp.FirstDayOfWeek != "" {
+		if wd, ok := weekdayFromGraphDay(gp.FirstDayOfWeek); ok {
+			rr.WorkWeekStart = wd
+		} else {
+			ce.Add("invalid firstDayOfWeek %q", gp.FirstDayOfWeek)
+		}
+	}
+
+	switch gp.Type {
+	case "daily":
+		rr.Frequency = DAILY
+	case "weekly":
+		rr.Frequency = WEEKLY
+		rr.ByDay = graphDaysOfWeek(gp, ce)
+		if len(rr.ByDay) == 0 {
+			ce.Add("weekly requires daysOfWeek")
+		}
+	case "absoluteMonthly":
+		rr.Frequency = MONTHLY
+		clampedMonthDayInto(rr, gp.DayOfMonth, 0, ce)
+	case "relativeMonthly":
+		rr.Frequency = MONTHLY
+		graphRelativeInto(rr, gp, ce)
+	case "absoluteYearly":
+		rr.Frequency = YEARLY
+		rr.ByMonth = []int16{int16(gp.Month)}
+		clampedMonthDayInto(rr, gp.DayOfMonth, gp.Month, ce)
+	case "relativeYearly":
+		rr.Frequency = YEARLY
+		rr.ByMonth = []int16{int16(gp.Month)}
+		graphRelativeInto(rr, gp, ce)
+	default:
+		ce.Add("unknown pattern type %q", gp.Type)
+	}
+
+	if rr.Frequency == YEARLY && (gp.Month < 1 || gp.Month > 12) {
+		ce.Add("invalid month %d", gp.Month)
+	}
+
+	switch gr.Range.Type {
+	case "noEnd":
+	case "endDate":
+		date, err := time.ParseInLocation(GraphDateFormat, gr.Range.EndDate, loc)
+		if err != nil {
+			ce.Add("invalid endDate %q", gr.Range.EndDate)
+			break
+		}
+		rr.Until = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, loc)
+	case "numbered":
+		if gr.Range.NumberOfOccurrences < 1 {
+			ce.Add("numbered requires numberOfOccurrences")
+		}
+		rr.Count = gr.Range.NumberOfOccurrences
+	default:
+		ce.Add("unknown range type %q", gr.Range.Type)
+	}
+
+	if err := ce.Err(); err != nil {
+		return rr, err
+	}
+
+	return rr, rr.internal_parser()
+}
+
+func (rr *RecurringRule) graphRelative(gp *GraphRecurrencePattern, ce *ConversionError) {
+	days, offset := rr.relativeDays(ce)
+
+	for _, fd := range days {
+		gp.DaysOfWeek = append(gp.DaysOfWeek, graphDayFromWeekday(fd.Weekday))
+	}
+
+	index, ok := graphIndexFromOffset(offset)
+	if !ok {
+		ce.Add("only the first four or the last day can be represented, got %d", offset)
+	}
+	gp.Index = index
+}
+
+// GraphPatternedRecurrence converts the rule into a Graph
+// patternedRecurrence. Graph can't express times of day, so the rule must
+// recur at the time of DtStart.
+func (rr *RecurringRule) GraphPatternedRecurrence() (*GraphPatternedRecurrence, error) {
+	ce := &ConversionError{Format: "Graph"}
+	gr := &GraphPatternedRecurrence{}
+	gp := &gr.Pattern
+
+	if rr.DtStart.Equal(EmptyTime) {
+		ce.Add("DtStart is required")
+	}
+
+	loc := rr.DtStart.Location()
+	if loc != time.Local {
+		gr.Range.RecurrenceTimeZone = loc.String()
+	}
+
+	gp.Interval = rr.Interval
+	if gp.Interval < 1 {
+		gp.Interval = 1
+	}
+	gp.FirstDayOfWeek = graphDayFromWeekday(rr.WorkWeekStart)
+
+	if len(rr.BySecond) > 0 || len(rr.ByMinute) > 0 || len(rr.ByHour) > 0 {
+		ce.Add("BYHOUR, BYMINUTE and BYSECOND can not be represented")
+	}
+	if len(rr.ByYearDay) > 0 || len(rr.ByWeekNo) > 0 {
+		ce.Add("BYYEARDAY and BYWEEKNO can not be represented")
+	}
+	if len(rr.ExceptionsToRule) > 0 {
+		ce.Add("EXDATE can not be represented")
+	}
+	if len(rr.ByMonth) > 1 {
+		ce.Add("BYMONTH can only have one month")
+	}
+
+	weekly := func() {
+		gp.Type = "weekly"
+		for _, fd := range rr.ByDay {
+			if fd.Offset != 0 {
+				ce.Add("BYDAY=%s can not be represented", fd)
+			}
+			gp.DaysOfWeek = append(gp.DaysOfWeek, graphDayFromWeekday(fd.Weekday))
+		}
+		if len(rr.ByDay) == 0 {
+			gp.DaysOfWeek = []string{graphDayFromWeekday(rr.DtStart.Weekday())}
+		}
+	}
+
+	switch rr.Frequency {
+	case DAILY:
+		switch {
+		case len(rr.ByMonthDay) > 0 || len(rr.ByMonth) > 0 || len(rr.BySetPos) > 0:
+			ce.Add("DAILY can only be limited by BYDAY")
+		case len(rr.ByDay) == 0:
+			gp.Type = "daily"
+		case gp.Interval == 1:
+			// Every weekday is a weekly pattern in Outlook.
+			weekly()
+		default:
+			ce.Add("DAILY with an INTERVAL and BYDAY can not be represented")
+		}
+	case WEEKLY:
+		if len(rr.ByMonthDay) > 0 || len(rr.ByMonth) > 0 || len(rr.BySetPos) > 0 {
+			ce.Add("WEEKLY can only be limited by BYDAY")
+		}
+		weekly()
+	case MONTHLY, YEARLY:
+		relative, absolute := "relativeMonthly", "absoluteMonthly"
+
+		if rr.Frequency == YEARLY {
+			relative, absolute = "relativeYearly", "absoluteYearly"
+
+			gp.Month = int(rr.DtStart.Month())
+			if len(rr.ByMonth) > 0 {
+				gp.Month = int(rr.ByMonth[0])
+			} else if len(rr.ByDay) > 0 {
+				ce.Add("BYDAY in a YEARLY rule requires BYMONTH")
+			}
+		} else if len(rr.ByMonth) > 0 {
+			ce.Add("BYMONTH in a MONTHLY rule can not be represented")
+		}
+
+		if len(rr.ByDay) > 0 {
+			if len(rr.ByMonthDay) > 0 {
+				ce.Add("BYDAY with BYMONTHDAY can not be represented")
+			}
+			gp.Type = relative
+			rr.graphRelative(gp, ce)
+		} else {
+			gp.Type = absolute
+			gp.DayOfMonth = rr.clampedMonthDay(gp.Month, ce)
+		}
+	default:
+		ce.Add("FREQ=%s can not be represented", rr.Frequency)
+	}
+
+	gr.Range.StartDate = rr.DtStart.Format(GraphDateFormat)
+
+	switch {
+	case rr.Count > 0:
+		gr.Range.Type = "numbered"
+		gr.Range.NumberOfOccurrences = rr.Count
+	case !rr.Until.Equal(EmptyTime):
+		// Every occurrence is at the time of DtStart, so the last day
+		// is only included when Until isn't earlier on that day.
+		until := rr.Until.In(loc)
+		last := time.Date(
+			until.Year(), until.Month(), until.Day(),
+			rr.DtStart.Hour(), rr.DtStart.Minute(), rr.DtStart.Second(), 0, loc,
+		)
+		if until.Before(last) {
+			last = last.AddDate(0, 0, -1)
+		}
+
+		gr.Range.Type = "endDate"
+		gr.Range.EndDate = last.Format(GraphDateFormat)
+	default:
+		gr.Range.Type = "noEnd"
+	}
+
+	return gr, ce.Err()
+}
@@ -0,0 +1,182 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func Test_Graph_Decode(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC)
+
+	var cases = map[string]string{
+		`{"pattern": {"type": "weekly", "interval": 2, "daysOfWeek": ["monday", "Wednesday"], "firstDayOfWeek": "monday"},
+		  "range": {"type": "endDate", "startDate": "2017-09-04", "endDate": "2017-12-31"}}`: "RRULE:FREQ=WEEKLY;INTERVAL=2;WKST=MO;BYDAY=MO,WE;UNTIL=20171231T235959Z",
+
+		`{"pattern": {"type": "relativeMonthly", "interval": 1, "daysOfWeek": ["friday"], "index": "last"},
+		  "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 5}}`: "RRULE:FREQ=MONTHLY;WKST=SU;BYDAY=-1FR;COUNT=5",
+
+		`{"pattern": {"type": "relativeMonthly", "interval": 1, "daysOfWeek": ["monday", "tuesday", "wednesday", "thursday", "friday"], "index": "first"},
+		  "range": {"type": "noEnd", "startDate": "2017-09-04"}}`: "RRULE:FREQ=MONTHLY;WKST=SU;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1",
+
+		`{"pattern": {"type": "absoluteMonthly", "interval": 3, "dayOfMonth": 15},
+		  "range": {"type": "noEnd", "startDate": "2017-09-04"}}`: "RRULE:FREQ=MONTHLY;INTERVAL=3;WKST=SU;BYMONTHDAY=15",
+
+		`{"pattern": {"type": "absoluteMonthly", "interval": 1, "dayOfMonth": 31},
+		  "range": {"type": "noEnd", "startDate": "2017-09-04"}}`: "RRULE:FREQ=MONTHLY;WKST=SU;BYMONTHDAY=28,29,30,31;BYSETPOS=-1",
+
+		`{"pattern": {"type": "absoluteYearly", "interval": 1, "month": 6, "dayOfMonth": 30},
+		  "range": {"type": "noEnd", "startDate": "2017-09-04"}}`: "RRULE:FREQ=YEARLY;WKST=SU;BYMONTH=6;BYMONTHDAY=30",
+
+		`{"pattern": {"type": "relativeYearly", "interval": 1, "month": 11, "daysOfWeek": ["thursday"], "index": "fourth"},
+		  "range": {"type": "noEnd", "startDate": "2017-09-04"}}`: "RRULE:FREQ=YEARLY;WKST=SU;BYMONTH=11;BYDAY=4TH",
+	}
+
+	for value, expected := range cases {
+		var gr GraphPatternedRecurrence
+		if err := json.Unmarshal([]byte(value), &gr); err != nil {
+			t.Fatal(err)
+		}
+
+		rule, err := RuleFromGraph(&gr, start)
+		if err != nil {
+			t.Log("Failed to convert", value, err)
+			t.Fail()
+			continue
+		}
+
+		expectedRule, _ := Parse("DTSTART;TZID=UTC:20170904T120000Z\n" + expected)
+		if !rule.Equal(expectedRule) {
+			t.Log("Rules don't match", expected, rule.RecurString())
+			t.Fail()
+		}
+	}
+}
+
+func Test_Graph_Occurrences(t *testing.T) {
+	// Day 31 falls back to the last day of shorter months, like Outlook.
+	gr := GraphPatternedRecurrence{
+		Pattern: GraphRecurrencePattern{Type: "absoluteMonthly", Interval: 1, DayOfMonth: 31},
+		Range:   GraphRecurrenceRange{Type: "numbered", StartDate: "2024-01-01", NumberOfOccurrences: 4},
+	}
+
+	rule, err := RuleFromGraph(&gr, time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []time.Time{
+		time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 30, 9, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	var index int
+
+	iter := rule.Iterator()
+	for iter.Step(&event) {
+		if index >= len(targets) || !event.Equal(targets[index]) {
+			t.Log("Unexpected occurrence", event)
+			t.Fail()
+			break
+		}
+		index += 1
+	}
+
+	if index != len(targets) {
+		t.Log("Failed to return enough results", index)
+		t.Fail()
+	}
+}
+
+func Test_Graph_Encode(t *testing.T) {
+	var cases = map[string]GraphPatternedRecurrence{
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=19971224T080000Z": {
+			Pattern: GraphRecurrencePattern{Type: "weekly", Interval: 2, DaysOfWeek: []string{"monday", "wednesday"}, FirstDayOfWeek: "monday"},
+			Range:   GraphRecurrenceRange{Type: "endDate", StartDate: "1997-09-02", EndDate: "1997-12-23", RecurrenceTimeZone: "UTC"},
+		},
+		"RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=10": {
+			Pattern: GraphRecurrencePattern{Type: "weekly", Interval: 1, DaysOfWeek: []string{"monday", "tuesday", "wednesday", "thursday", "friday"}, FirstDayOfWeek: "monday"},
+			Range:   GraphRecurrenceRange{Type: "numbered", StartDate: "1997-09-02", NumberOfOccurrences: 10, RecurrenceTimeZone: "UTC"},
+		},
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1": {
+			Pattern: GraphRecurrencePattern{Type: "relativeMonthly", Interval: 1, DaysOfWeek: []string{"monday", "tuesday", "wednesday", "thursday", "friday"}, FirstDayOfWeek: "monday", Index: "last"},
+			Range:   GraphRecurrenceRange{Type: "noEnd", StartDate: "1997-09-02", RecurrenceTimeZone: "UTC"},
+		},
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1": {
+			Pattern: GraphRecurrencePattern{Type: "absoluteMonthly", Interval: 1, DayOfMonth: 31, FirstDayOfWeek: "monday"},
+			Range:   GraphRecurrenceRange{Type: "noEnd", StartDate: "1997-09-02", RecurrenceTimeZone: "UTC"},
+		},
+		"RRULE:FREQ=YEARLY": {
+			Pattern: GraphRecurrencePattern{Type: "absoluteYearly", Interval: 1, Month: 9, DayOfMonth: 2, FirstDayOfWeek: "monday"},
+			Range:   GraphRecurrenceRange{Type: "noEnd", StartDate: "1997-09-02", RecurrenceTimeZone: "UTC"},
+		},
+	}
+
+	for value, expected := range cases {
+		rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\n" + value)
+
+		result, err := rule.GraphPatternedRecurrence()
+		if err != nil {
+			t.Log("Failed to convert", value, err)
+			t.Fail()
+			continue
+		}
+
+		got, _ := json.Marshal(result)
+		want, _ := json.Marshal(expected)
+
+		if string(got) != string(want) {
+			t.Log("Patterns don't match", value, string(got))
+			t.Fail()
+		}
+	}
+
+	var unsupported = []string{
+		"RRULE:FREQ=HOURLY",
+		"RRULE:FREQ=DAILY;BYHOUR=9,17",
+		"RRULE:FREQ=MONTHLY;BYDAY=5MO",
+		"RRULE:FREQ=MONTHLY;BYDAY=1MO,-1FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=30",
+		"RRULE:FREQ=YEARLY;BYDAY=1MO",
+		"RRULE:FREQ=YEARLY;BYMONTH=1,7",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\n" + value)
+
+		if _, err := rule.GraphPatternedRecurrence(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Graph_RoundTrip(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"RRULE:FREQ=YEARLY;INTERVAL=2;WKST=SU;BYMONTH=2;BYMONTHDAY=28,29;BYSETPOS=-1;COUNT=4",
+	)
+
+	gr, err := rule.GraphPatternedRecurrence()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gr.Pattern.DayOfMonth != 29 || gr.Range.RecurrenceTimeZone != "America/New_York" {
+		t.Log("Unexpected pattern", gr)
+		t.Fail()
+	}
+
+	result, err := RuleFromGraph(gr, rule.DtStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Equal(rule) {
+		t.Log("Round trip failed", result)
+		t.Fail()
+	}
+}
@@ -0,0 +1,415 @@
+package rrule
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Some consumers can't expand recurrences themselves. WriteExpandedICal
+// writes every instance of a rule within a window as its own VEVENT, each
+// with the UID of the series and a RECURRENCE-ID, so they can still be
+// matched up with the recurring event by consumers that do understand it.
+
+// ICalProperty is a property copied onto every instance. Name may carry
+// parameters, "LOCATION;LANGUAGE=en". Values of text properties such as
+// SUMMARY are escaped, others are written as they are.
+type ICalProperty struct {
+	Name  string
+	Value string
+}
+
+// ICalEvent is a recurring event to expand.
+type ICalEvent struct {
+	UID  string
+	Rule *RecurringRule
+
+	// End is DTEND of the first instance, or Duration is the length of
+	// every instance. Neither is needed for events without a length.
+	End      time.Time
+	Duration time.Duration
+
+	Properties []ICalProperty
+
+	// Stamp is DTSTAMP, the current time when it isn't set.
+	Stamp time.Time
+}
+
+const icalProductID = "-//graham//rrule//EN"
+
+// The longest a content line can be, in octets, before it's folded.
+const icalLineLength = 75
+
+// How many periods are searched for instances in the window.
+const icalHardLimit = 100000
+
+var icalTextProperties = map[string]bool{
+	"SUMMARY": true, "DESCRIPTION": true, "LOCATION": true, "COMMENT": true,
+	"CONTACT": true, "CATEGORIES": true, "RESOURCES": true, "X-WR-CALNAME": true,
+	"X-WR-CALDESC": true,
+}
+
+// WriteExpandedICal writes a VCALENDAR with a VEVENT for every instance of
+// the event that overlaps [after, before), along with a VTIMEZONE for the
+// location of DtStart. Instances are written as they're found.
+func WriteExpandedICal(w io.Writer, event *ICalEvent, after, before time.Time) error {
+	if err := event.check(); err != nil {
+		return err
+	}
+
+	iw := NewICalWriter(w)
+	if icalHasTZID(event.Rule.DtStart.Location()) {
+		iw.Timezone(event.Rule.DtStart.Location(), after.Add(-event.Length()), before)
+	}
+
+	if err := iw.Expanded(event, after, before); err != nil {
+		return err
+	}
+	return iw.Close()
+}
+
+func (event *ICalEvent) check() error {
+	if event.UID == "" {
+		return errors.New("an event needs a UID")
+	}
+	if event.Rule == nil {
+		return errors.New("an event needs a rule")
+	}
+	if event.Length() < 0 {
+		return errors.New(fmt.Sprintf("event %s ends before it starts", event.UID))
+	}
+	return nil
+}
+
+// Length is how long every instance lasts.
+func (event *ICalEvent) Length() time.Duration {
+	if !event.End.Equal(EmptyTime) {
+		return event.End.Sub(event.Rule.DtStart)
+	}
+	return event.Duration
+}
+
+func (event *ICalEvent) stamp() time.Time {
+	if event.Stamp.Equal(EmptyTime) {
+		return time.Now().UTC()
+	}
+	return event.Stamp.UTC()
+}
+
+// ICalWriter writes a VCALENDAR a component at a time, for calendars with
+// more than one event. Errors are kept until Close.
+type ICalWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewICalWriter starts a VCALENDAR, properties such as X-WR-CALNAME are
+// added to it.
+func NewICalWriter(w io.Writer, properties ...ICalProperty) *ICalWriter {
+	iw := &ICalWriter{w: bufio.NewWriter(w)}
+
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:" + icalProductID)
+	iw.properties(properties)
+
+	return iw
+}
+
+// Close ends the VCALENDAR and flushes it.
+func (iw *ICalWriter) Close() error {
+	iw.line("END:VCALENDAR")
+
+	if iw.err != nil {
+		return iw.err
+	}
+	return iw.w.Flush()
+}
+
+// Expanded writes a VEVENT for every instance of the event that overlaps
+// [after, before). VTIMEZONEs must be written before it.
+func (iw *ICalWriter) Expanded(event *ICalEvent, after, before time.Time) error {
+	if err := event.check(); err != nil {
+		return err
+	}
+
+	// Instances that started up to a duration before after are still
+	// going. After is exclusive, and an instance with no duration starting
+	// at after is in the window.
+	duration := event.Length()
+	iter := event.Rule.Iterator().
+		Between(after.Add(-duration).Add(-time.Nanosecond), before).
+		HardLimit(icalHardLimit)
+
+	var start time.Time
+	for iter.Step(&start) {
+		end := start.Add(duration)
+		if !end.After(after) && !(duration == 0 && start.Equal(after)) {
+			continue
+		}
+
+		iw.line("BEGIN:VEVENT")
+		iw.line("UID:" + icalEscape(event.UID))
+		iw.line("DTSTAMP:" + DateTimeToString(event.stamp()))
+		iw.line(icalDateTime("RECURRENCE-ID", start))
+		iw.line(icalDateTime("DTSTART", start))
+		iw.end(event, end)
+		iw.properties(event.Properties)
+		iw.line("END:VEVENT")
+
+		if iw.err != nil {
+			return iw.err
+		}
+	}
+
+	if iter.IsHardLimitReached() {
+		return errors.New(fmt.Sprintf("no end to the instances of %s was found", event.UID))
+	}
+	return iw.err
+}
+
+// Recurring writes the event as a single VEVENT with its RRULE and
+// EXDATEs, for consumers that expand it themselves.
+func (iw *ICalWriter) Recurring(event *ICalEvent) error {
+	if err := event.check(); err != nil {
+		return err
+	}
+
+	rule := event.Rule.Clone()
+	loc := rule.DtStart.Location()
+
+	// UNTIL is in UTC when DTSTART has a time zone.
+	if !rule.Until.Equal(EmptyTime) && loc != time.Local {
+		rule.Until = rule.Until.UTC()
+	}
+
+	iw.line("BEGIN:VEVENT")
+	iw.line("UID:" + icalEscape(event.UID))
+	iw.line("DTSTAMP:" + DateTimeToString(event.stamp()))
+	iw.line(icalDateTime("DTSTART", rule.DtStart))
+	iw.end(event, rule.DtStart.Add(event.Length()))
+	iw.line(rule.RecurString())
+
+	if len(rule.ExceptionsToRule) > 0 {
+		iw.line(icalDateTimes("EXDATE", loc, rule.ExceptionsToRule))
+	}
+
+	iw.properties(event.Properties)
+	iw.line("END:VEVENT")
+
+	return iw.err
+}
+
+// end writes DURATION when the event was given one, DTEND otherwise.
+func (iw *ICalWriter) end(event *ICalEvent, end time.Time) {
+	switch {
+	case !event.End.Equal(EmptyTime):
+		iw.line(icalDateTime("DTEND", end.In(event.Rule.DtStart.Location())))
+	case event.Duration > 0:
+		iw.line("DURATION:" + DurationToString(event.Duration))
+	}
+}
+
+func (iw *ICalWriter) properties(properties []ICalProperty) {
+	for _, p := range properties {
+		name, _, _ := strings.Cut(p.Name, ";")
+		value := p.Value
+		if icalTextProperties[strings.ToUpper(name)] {
+			value = icalEscape(value)
+		}
+		iw.line(p.Name + ":" + value)
+	}
+}
+
+// line writes a content line, folded after 75 octets without splitting a
+// UTF-8 sequence.
+func (iw *ICalWriter) line(content string) {
+	if iw.err != nil {
+		return
+	}
+
+	limit := icalLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+
+		_, iw.err = iw.w.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+
+		// The space starting a continuation counts towards its length.
+		limit = icalLineLength - 1
+	}
+
+	if iw.err == nil {
+		_, iw.err = iw.w.WriteString(content + "\r\n")
+	}
+}
+
+type icalObservance struct {
+	kind     string
+	start    time.Time
+	from, to int
+	name     string
+	rule     string
+}
+
+// Timezone writes a VTIMEZONE with the observance in effect at from and
+// every transition up to until. When the zone keeps changing after until,
+// the last two observances repeat every year, so consumers get instances
+// past the window right too.
+func (iw *ICalWriter) Timezone(loc *time.Location, from, until time.Time) {
+	var observances []icalObservance
+
+	t := from.In(loc)
+	for {
+		onset, end := t.ZoneBounds()
+		name, offset := t.Zone()
+
+		previous := offset
+		if onset.Equal(EmptyTime) {
+			onset = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(offset) * time.Second)
+		} else {
+			_, previous = onset.Add(-time.Second).Zone()
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+
+		// The onset is written in local time before the transition.
+		local := onset.UTC().Add(time.Duration(previous) * time.Second)
+
+		observances = append(observances, icalObservance{
+			kind: kind, start: local, from: previous, to: offset, name: name,
+		})
+
+		if end.Equal(EmptyTime) {
+			break
+		}
+		if !end.Before(until) {
+			observances = icalRepeatObservances(observances, end.In(loc))
+			break
+		}
+		t = end.In(loc)
+	}
+
+	iw.line("BEGIN:VTIMEZONE")
+	iw.line("TZID:" + loc.String())
+
+	for _, o := range observances {
+		iw.line("BEGIN:" + o.kind)
+		iw.line("DTSTART:" + strings.TrimSuffix(DateTimeToString(o.start), "Z"))
+		iw.line("TZOFFSETFROM:" + icalOffset(o.from))
+		iw.line("TZOFFSETTO:" + icalOffset(o.to))
+		iw.line("TZNAME:" + o.name)
+		if o.rule != "" {
+			iw.line(o.rule)
+		}
+		iw.line("END:" + o.kind)
+	}
+
+	iw.line("END:VTIMEZONE")
+}
+
+// icalRepeatObservances adds a yearly RRULE to the last standard and
+// daylight observance when the zone switches between them every year.
+// next is the first transition after the last observance.
+func icalRepeatObservances(observances []icalObservance, next time.Time) []icalObservance {
+	last := &observances[len(observances)-1]
+
+	// The observance after the window, it starts at next.
+	_, offset := next.Zone()
+	_, end := next.ZoneBounds()
+	if end.Equal(EmptyTime) || end.Sub(next) > 366*24*time.Hour || offset != last.from {
+		return observances
+	}
+
+	kind := "STANDARD"
+	if next.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	if kind == last.kind {
+		return observances
+	}
+
+	name, _ := next.Zone()
+	observances = append(observances, icalObservance{
+		kind:  kind,
+		start: next.UTC().Add(time.Duration(last.to) * time.Second),
+		from:  last.to,
+		to:    offset,
+		name:  name,
+	})
+
+	for i := len(observances) - 2; i < len(observances); i++ {
+		observances[i].rule = icalYearlyRule(observances[i].start)
+	}
+	return observances
+}
+
+// icalYearlyRule repeats a transition on the same weekday of the month
+// every year, the last one when it's in the last week of the month.
+func icalYearlyRule(start time.Time) string {
+	days := time.Date(start.Year(), start.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	n := (start.Day()-1)/7 + 1
+	if start.Day()+7 > days {
+		n = -1
+	}
+
+	return fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%s",
+		int(start.Month()), ForDay{Weekday: start.Weekday(), Offset: n})
+}
+
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+
+	if seconds%60 != 0 {
+		return fmt.Sprintf("%s%02d%02d%02d", sign, seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// icalHasTZID is false for UTC, which is written with a Z, and for Local,
+// which is written as floating time.
+func icalHasTZID(loc *time.Location) bool {
+	return loc != time.UTC && loc != time.Local
+}
+
+func icalDateTime(name string, t time.Time) string {
+	return icalDateTimes(name, t.Location(), []time.Time{t})
+}
+
+// icalDateTimes writes a property with a list of times in loc.
+func icalDateTimes(name string, loc *time.Location, times []time.Time) string {
+	var values []string
+	for _, t := range times {
+		values = append(values, DateTimeToString(t.In(loc)))
+	}
+
+	if icalHasTZID(loc) {
+		return fmt.Sprintf("%s;TZID=%s:%s", name, loc.String(), strings.Join(values, ","))
+	}
+	return name + ":" + strings.Join(values, ",")
+}
+
+func icalEscape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
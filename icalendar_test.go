@@ -0,0 +1,219 @@
+package rrule
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unfoldICal returns the content lines of a calendar, checking the line
+// endings and lengths on the way.
+func unfoldICal(t *testing.T, data string) []string {
+	if !strings.HasSuffix(data, "\r\n") {
+		t.Log("Calendar doesn't end with CRLF")
+		t.Fail()
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Log("Line is longer than 75 octets", line)
+			t.Fail()
+		}
+		if strings.Contains(line, "\n") {
+			t.Log("Bare LF in line", line)
+			t.Fail()
+		}
+
+		if strings.HasPrefix(line, " ") && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func countLines(lines []string, prefix string) int {
+	count := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			count += 1
+		}
+	}
+	return count
+}
+
+func Test_WriteExpandedICal(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=America/New_York:20241021T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE")
+	loc := rule.DtStart.Location()
+
+	description := strings.Repeat("Bring the quarterly numbers, slides; and coffee. ", 4) + "Über alles."
+
+	event := &ICalEvent{
+		UID:   "standup@example.com",
+		Rule:  rule,
+		End:   time.Date(2024, time.October, 21, 9, 30, 0, 0, loc),
+		Stamp: time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+		Properties: []ICalProperty{
+			{"SUMMARY", "Standup, team A"},
+			{"DESCRIPTION", description},
+			{"STATUS", "CONFIRMED"},
+		},
+	}
+
+	var buffer bytes.Buffer
+	after := time.Date(2024, time.October, 28, 0, 0, 0, 0, loc)
+	before := time.Date(2024, time.November, 7, 0, 0, 0, 0, loc)
+
+	if err := WriteExpandedICal(&buffer, event, after, before); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := unfoldICal(t, buffer.String())
+
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Log("Missing VCALENDAR", lines[0], lines[len(lines)-1])
+		t.Fail()
+	}
+
+	// Oct 28, Oct 30, Nov 4 and Nov 6, across the end of daylight time.
+	expected := []string{
+		"RECURRENCE-ID;TZID=America/New_York:20241028T090000",
+		"RECURRENCE-ID;TZID=America/New_York:20241030T090000",
+		"RECURRENCE-ID;TZID=America/New_York:20241104T090000",
+		"RECURRENCE-ID;TZID=America/New_York:20241106T090000",
+	}
+
+	var found []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "RECURRENCE-ID") {
+			found = append(found, line)
+		}
+	}
+	if strings.Join(found, "\n") != strings.Join(expected, "\n") {
+		t.Log("Unexpected instances", found)
+		t.Fail()
+	}
+
+	var counts = map[string]int{
+		"BEGIN:VEVENT":                                4,
+		"UID:standup@example.com":                     4,
+		"DTSTAMP:20241001T120000Z":                    4,
+		"DTEND;TZID=America/New_York:20241104T093000": 1,
+		"SUMMARY:Standup\\, team A":                   4,
+		"STATUS:CONFIRMED":                            4,
+		"BEGIN:VTIMEZONE":                             1,
+		"BEGIN:DAYLIGHT":                              2,
+		"BEGIN:STANDARD":                              1,
+		"DTSTART:20241103T020000":                     1,
+		"DTSTART:20250309T020000":                     1,
+		"TZOFFSETFROM:-0400":                          1,
+		"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU":      1,
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU":       1,
+	}
+	for prefix, count := range counts {
+		if n := countLines(lines, prefix); n != count {
+			t.Log("Unexpected number of lines", prefix, n)
+			t.Fail()
+		}
+	}
+
+	escaped := "DESCRIPTION:" + strings.NewReplacer(",", `\,`, ";", `\;`).Replace(description)
+	if countLines(lines, escaped) != 4 {
+		t.Log("Description wasn't folded and escaped correctly")
+		t.Fail()
+	}
+}
+
+func Test_WriteExpandedICal_UTC(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T080000Z\nRRULE:FREQ=DAILY;COUNT=5")
+
+	event := &ICalEvent{UID: "daily", Rule: rule, Duration: 90 * time.Minute}
+
+	var buffer bytes.Buffer
+	after := time.Date(2024, time.January, 2, 9, 0, 0, 0, time.UTC)
+	if err := WriteExpandedICal(&buffer, event, after, after.AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := unfoldICal(t, buffer.String())
+
+	// Jan 2 is still going at 9:00.
+	if countLines(lines, "RECURRENCE-ID:20240102T080000Z") != 1 || countLines(lines, "BEGIN:VEVENT") != 4 {
+		t.Log("Unexpected instances", lines)
+		t.Fail()
+	}
+
+	if countLines(lines, "DURATION:PT1H30M") != 4 || countLines(lines, "BEGIN:VTIMEZONE") != 0 {
+		t.Log("Unexpected properties", lines)
+		t.Fail()
+	}
+
+	if err := WriteExpandedICal(&buffer, &ICalEvent{Rule: rule}, after, after); err == nil {
+		t.Log("Expected an error without a UID")
+		t.Fail()
+	}
+}
+
+func Test_WriteExpandedICal_OldStart(t *testing.T) {
+	// Years of half hours before the window aren't stepped through.
+	rule, _ := Parse("DTSTART;TZID=UTC:20200101T000000Z\nRRULE:FREQ=MINUTELY;INTERVAL=30")
+
+	event := &ICalEvent{UID: "rotation", Rule: rule, Duration: time.Hour}
+
+	var buffer bytes.Buffer
+	after := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := WriteExpandedICal(&buffer, event, after, after.AddDate(0, 0, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The one from 23:30 is still going at midnight.
+	lines := unfoldICal(t, buffer.String())
+	if countLines(lines, "BEGIN:VEVENT") != 49 || countLines(lines, "RECURRENCE-ID:20251231T233000Z") != 1 {
+		t.Log("Unexpected instances", countLines(lines, "BEGIN:VEVENT"))
+		t.Fail()
+	}
+}
+
+func Test_ICalWriter_Recurring(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=Europe/Berlin:20240102T180000\nRRULE:FREQ=WEEKLY;UNTIL=20240301T000000;BYDAY=TU,TH")
+	loc := rule.DtStart.Location()
+	rule = rule.WithExceptions(
+		time.Date(2024, time.January, 4, 18, 0, 0, 0, loc),
+		time.Date(2024, time.January, 9, 17, 0, 0, 0, time.UTC),
+	)
+
+	var buffer bytes.Buffer
+	iw := NewICalWriter(&buffer, ICalProperty{"X-WR-CALNAME", "Training, evenings"})
+	iw.Timezone(loc, rule.DtStart, rule.Until)
+	err := iw.Recurring(&ICalEvent{UID: "training", Rule: rule, Duration: 2 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := unfoldICal(t, buffer.String())
+
+	var counts = map[string]int{
+		"X-WR-CALNAME:Training\\, evenings":                         1,
+		"BEGIN:VEVENT":                                              1,
+		"DTSTART;TZID=Europe/Berlin:20240102T180000":                1,
+		"DURATION:PT2H":                                             1,
+		"RRULE:FREQ=WEEKLY;UNTIL=20240229T230000Z;BYDAY=TU,TH":      1,
+		"EXDATE;TZID=Europe/Berlin:20240104T180000,20240109T180000": 1,
+		"RECURRENCE-ID":                                             0,
+		"TZID:Europe/Berlin":                                        1,
+		"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU":                   1,
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU":                    1,
+	}
+	for prefix, count := range counts {
+		if n := countLines(lines, prefix); n != count {
+			t.Log("Unexpected number of lines", prefix, n)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISO 8601 describes schedules as repeating intervals, for example
+// "R5/2024-03-01T13:00:00Z/P1Y2M10DT2H30M". Only the simple ones (a
+// period made of a single unit) have an RRULE equivalent, the rest can
+// be expanded with ISOIterator.
+
+// ISOPeriod is a nominal ISO 8601 duration. Years, months, weeks and days
+// are calendar units, they keep the wall clock time across DST changes.
+type ISOPeriod struct {
+	Years   int
+	Months  int
+	Weeks   int
+	Days    int
+	Hours   int
+	Minutes int
+	Seconds int
+}
+
+// ParseISOPeriod parses durations in the PnYnMnWnDTnHnMnS format.
+// Fractional values are not supported.
+func ParseISOPeriod(s string) (ISOPeriod, error) {
+	var period ISOPeriod
+
+	if len(s) < 2 || s[0] != 'P' {
+		return period, BadFormatError(s)
+	}
+
+	var chunk strings.Builder
+	var inTime bool
+	var seenUnit bool
+
+	for _, c := range s[1:] {
+		if c >= '0' && c <= '9' {
+			chunk.WriteRune(c)
+			continue
+		}
+
+		if c == 'T' {
+			if inTime || chunk.Len() > 0 {
+				return period, BadFormatError(s)
+			}
+			inTime = true
+			continue
+		}
+
+		value, err := strconv.Atoi(chunk.String())
+		if err != nil {
+			return period, BadFormatError(s)
+		}
+		chunk.Reset()
+		seenUnit = true
+
+		switch {
+		case c == 'Y' && !inTime:
+			period.Years = value
+		case c == 'M' && !inTime:
+			period.Months = value
+		case c == 'W' && !inTime:
+			period.Weeks = value
+		case c == 'D' && !inTime:
+			period.Days = value
+		case c == 'H' && inTime:
+			period.Hours = value
+		case c == 'M' && inTime:
+			period.Minutes = value
+		case c == 'S' && inTime:
+			period.Seconds = value
+		default:
+			return period, BadFormatError(s)
+		}
+	}
+
+	if chunk.Len() > 0 || !seenUnit {
+		return period, BadFormatError(s)
+	}
+
+	if period.IsZero() {
+		return period, BadFormatError("period must not be zero")
+	}
+
+	return period, nil
+}
+
+func (p ISOPeriod) IsZero() bool {
+	return p == ISOPeriod{}
+}
+
+// AddTo returns t plus n times the period. Years and months are added
+// first, and a day past the end of the month they land in is the last day
+// of it, as in ISO 8601 and XML Schema: P1M from January 31st is February
+// 29th in a leap year. Then days, then the exact hours, minutes and seconds.
+func (p ISOPeriod) AddTo(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	months := y*12 + int(m) - 1 + (p.Years*12+p.Months)*n
+	year := floorDiv(months, 12)
+	month := months - year*12 + 1
+
+	t = time.Date(
+		year, time.Month(month), min(d, daysInMonth(year, month)),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location(),
+	).AddDate(0, 0, (p.Weeks*7+p.Days)*n)
+
+	exact := time.Duration(p.Hours)*time.Hour +
+		time.Duration(p.Minutes)*time.Minute +
+		time.Duration(p.Seconds)*time.Second
+
+	return t.Add(exact * time.Duration(n))
+}
+
+func (p ISOPeriod) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("P")
+	for _, part := range []struct {
+		value int
+		unit  string
+	}{{p.Years, "Y"}, {p.Months, "M"}, {p.Weeks, "W"}, {p.Days, "D"}} {
+		if part.value != 0 {
+			fmt.Fprintf(&sb, "%d%s", part.value, part.unit)
+		}
+	}
+
+	if p.Hours != 0 || p.Minutes != 0 || p.Seconds != 0 {
+		sb.WriteString("T")
+		for _, part := range []struct {
+			value int
+			unit  string
+		}{{p.Hours, "H"}, {p.Minutes, "M"}, {p.Seconds, "S"}} {
+			if part.value != 0 {
+				fmt.Fprintf(&sb, "%d%s", part.value, part.unit)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// singleUnit returns the frequency and interval of a period made of one
+// unit, ok is false when the period mixes units.
+func (p ISOPeriod) singleUnit() (freq FrequencyValue, interval int, ok bool) {
+	var found int
+
+	for _, part := range []struct {
+		value int
+		freq  FrequencyValue
+	}{
+		{p.Years, YEARLY},
+		{p.Months, MONTHLY},
+		{p.Weeks, WEEKLY},
+		{p.Days, DAILY},
+		{p.Hours, HOURLY},
+		{p.Minutes, MINUTELY},
+		{p.Seconds, SECONDLY},
+	} {
+		if part.value != 0 {
+			freq = part.freq
+			interval = part.value
+			found += 1
+		}
+	}
+
+	return freq, interval, found == 1
+}
+
+// ISORepeatingInterval is a parsed ISO 8601 repeating interval.
+// Repetitions is the number of occurrences, -1 for an unbounded interval.
+type ISORepeatingInterval struct {
+	Repetitions int
+	Start       time.Time
+	Period      ISOPeriod
+}
+
+func parseISOTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "20060102T150405Z0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05", "20060102T150405", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return EmptyTime, BadFormatError(s)
+}
+
+// ParseISORepeatingInterval parses "Rn/start/period", "Rn/start/end" and
+// "Rn/period/end". "R" and "R-1" are unbounded. Times without a zone
+// designator are read in loc.
+func ParseISORepeatingInterval(s string, loc *time.Location) (*ISORepeatingInterval, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, BadFormatError(s)
+	}
+
+	ri := &ISORepeatingInterval{Repetitions: -1}
+
+	if parts[0] != "R" && parts[0] != "R-1" {
+		n, err := strconv.Atoi(parts[0][1:])
+		if err != nil || n < 0 {
+			return nil, BadFormatError(parts[0])
+		}
+		ri.Repetitions = n
+	}
+
+	switch {
+	case strings.HasPrefix(parts[1], "P"):
+		period, err := ParseISOPeriod(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseISOTime(parts[2], loc)
+		if err != nil {
+			return nil, err
+		}
+		if ri.Repetitions < 0 {
+			return nil, errors.New("an unbounded interval can't end at a date")
+		}
+		ri.Period = period
+		ri.Start = period.AddTo(end, -ri.Repetitions)
+	case strings.HasPrefix(parts[2], "P"):
+		period, err := ParseISOPeriod(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseISOTime(parts[1], loc)
+		if err != nil {
+			return nil, err
+		}
+		ri.Start = start
+		ri.Period = period
+	default:
+		start, err := parseISOTime(parts[1], loc)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseISOTime(parts[2], loc)
+		if err != nil {
+			return nil, err
+		}
+		if !end.After(start) {
+			return nil, BadFormatError("end must be after start")
+		}
+
+		// An explicit start and end is an exact duration, so
+		// it's expressed in the largest exact unit.
+		seconds := int(end.Sub(start) / time.Second)
+		switch {
+		case seconds%3600 == 0:
+			ri.Period = ISOPeriod{Hours: seconds / 3600}
+		case seconds%60 == 0:
+			ri.Period = ISOPeriod{Minutes: seconds / 60}
+		default:
+			ri.Period = ISOPeriod{Seconds: seconds}
+		}
+		ri.Start = start
+	}
+
+	return ri, nil
+}
+
+func (ri *ISORepeatingInterval) String() string {
+	var repeat string = "R"
+	if ri.Repetitions >= 0 {
+		repeat = fmt.Sprintf("R%d", ri.Repetitions)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", repeat, ri.Start.Format(time.RFC3339), ri.Period)
+}
+
+// isoMonthEndProblem reports when calendar arithmetic and RRULE disagree:
+// adding a month to the 31st gives the end of shorter months, while an
+// RRULE skips the months that don't have that day. Years only differ from
+// February 29th.
+func isoMonthEndProblem(freq FrequencyValue, start time.Time) bool {
+	switch freq {
+	case YEARLY:
+		return start.Month() == time.February && start.Day() == 29
+	case MONTHLY:
+		return start.Day() > 28
+	}
+	return false
+}
+
+// Rule converts the interval into an equivalent RecurringRule, this is
+// only possible when the period is a single unit.
+func (ri *ISORepeatingInterval) Rule() (*RecurringRule, error) {
+	ce := &ConversionError{Format: "ISO 8601"}
+
+	freq, interval, ok := ri.Period.singleUnit()
+	if !ok {
+		ce.Add("period %s mixes units and has no RRULE equivalent", ri.Period)
+	}
+
+	if ri.Repetitions == 0 {
+		ce.Add("R0 has no occurrences")
+	}
+
+	if ok && isoMonthEndProblem(freq, ri.Start) {
+		ce.Add("period %s from day %d has no RRULE equivalent", ri.Period, ri.Start.Day())
+	}
+
+	if err := ce.Err(); err != nil {
+		return nil, err
+	}
+
+	rr := &RecurringRule{
+		DtStart:       ri.Start,
+		Frequency:     freq,
+		Interval:      interval,
+		WorkWeekStart: time.Monday,
+	}
+
+	if ri.Repetitions >= 0 {
+		rr.Count = ri.Repetitions
+	}
+
+	return rr, nil
+}
+
+// ISORepeatingInterval converts simple rules (FREQ, INTERVAL, COUNT and
+// UNTIL only) into a repeating interval. UNTIL is converted into the
+// number of occurrences it allows.
+func (rr *RecurringRule) ISORepeatingInterval() (*ISORepeatingInterval, error) {
+	ce := &ConversionError{Format: "ISO 8601"}
+
+	if rr.DtStart.Equal(EmptyTime) {
+		ce.Add("DtStart is required")
+	}
+
+	if rr.hasByParts() {
+		ce.Add("BYxxx rule parts can not be represented")
+	}
+
+	if len(rr.ExceptionsToRule) > 0 {
+		ce.Add("EXDATE can not be represented")
+	}
+
+	if isoMonthEndProblem(rr.Frequency, rr.DtStart) {
+		ce.Add("FREQ=%s from day %d can not be represented", rr.Frequency, rr.DtStart.Day())
+	}
+
+	if err := ce.Err(); err != nil {
+		return nil, err
+	}
+
+	interval := rr.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	ri := &ISORepeatingInterval{Repetitions: -1, Start: rr.DtStart}
+
+	switch rr.Frequency {
+	case YEARLY:
+		ri.Period.Years = interval
+	case MONTHLY:
+		ri.Period.Months = interval
+	case WEEKLY:
+		ri.Period.Weeks = interval
+	case DAILY:
+		ri.Period.Days = interval
+	case HOURLY:
+		ri.Period.Hours = interval
+	case MINUTELY:
+		ri.Period.Minutes = interval
+	case SECONDLY:
+		ri.Period.Seconds = interval
+	}
+
+	if rr.Count > 0 {
+		ri.Repetitions = rr.Count
+	} else if !rr.Until.Equal(EmptyTime) {
+		var event time.Time
+		var count int
+
+		iter := rr.Iterator()
+		for iter.Step(&event) {
+			count += 1
+		}
+		ri.Repetitions = count
+	}
+
+	return ri, nil
+}
+
+// ISOIterator expands a repeating interval, with the same Step, Limit,
+// Before, After and Between semantics as RecurrenceIterator.
+type ISOIterator struct {
+	interval    *ISORepeatingInterval
+	iterCounter int
+
+	ReturnCounter int
+
+	UserLimit int
+
+	UserBefore time.Time
+	UserAfter  time.Time
+
+	UseUserBefore bool
+	UseUserAfter  bool
+}
+
+func (ri *ISORepeatingInterval) Iterator() *ISOIterator {
+	return &ISOIterator{interval: ri}
+}
+
+func (ii *ISOIterator) Limit(i int) *ISOIterator {
+	ii.UserLimit = i
+	return ii
+}
+
+func (ii *ISOIterator) Before(b time.Time) *ISOIterator {
+	ii.UserBefore = b
+	ii.UseUserBefore = true
+	return ii
+}
+
+func (ii *ISOIterator) After(a time.Time) *ISOIterator {
+	ii.UserAfter = a
+	ii.UseUserAfter = true
+	return ii
+}
+
+func (ii *ISOIterator) Between(a, b time.Time) *ISOIterator {
+	ii.After(a)
+	ii.Before(b)
+	return ii
+}
+
+func (ii *ISOIterator) Step(t *time.Time) bool {
+	for {
+		if ii.interval.Repetitions >= 0 && ii.iterCounter >= ii.interval.Repetitions {
+			return false
+		}
+
+		if ii.UserLimit > 0 && ii.ReturnCounter >= ii.UserLimit {
+			return false
+		}
+
+		next := ii.interval.Period.AddTo(ii.interval.Start, ii.iterCounter)
+		ii.iterCounter += 1
+
+		if ii.UseUserBefore && !next.Before(ii.UserBefore) {
+			return false
+		}
+
+		if ii.UseUserAfter && !next.After(ii.UserAfter) {
+			continue
+		}
+
+		*t = next
+		ii.ReturnCounter += 1
+		return true
+	}
+}
@@ -0,0 +1,202 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ISOPeriod_Parse(t *testing.T) {
+	period, err := ParseISOPeriod("P1Y2M10DT2H30M")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if period != (ISOPeriod{Years: 1, Months: 2, Days: 10, Hours: 2, Minutes: 30}) {
+		t.Log("Failed to parse period", period)
+		t.Fail()
+	}
+
+	if period.String() != "P1Y2M10DT2H30M" {
+		t.Log("Failed to format period", period)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"P", "PT", "1Y", "P1H", "PT1D", "P1.5Y", "P0D"} {
+		if _, err := ParseISOPeriod(bad); err == nil {
+			t.Log("Expected error for", bad)
+			t.Fail()
+		}
+	}
+}
+
+func Test_ISORepeatingInterval_ToRule(t *testing.T) {
+	ri, err := ParseISORepeatingInterval("R5/1997-09-02T09:00:00Z/P2W", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := ri.Rule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=5")
+
+	if !rule.Equal(expected) {
+		t.Log("Rules don't match", rule)
+		t.Fail()
+	}
+
+	back, err := rule.ISORepeatingInterval()
+	if err != nil || back.String() != "R5/1997-09-02T09:00:00Z/P2W" {
+		t.Log("Failed to convert back", back, err)
+		t.Fail()
+	}
+}
+
+func Test_ISORepeatingInterval_Forms(t *testing.T) {
+	start := time.Date(2024, 3, 1, 13, 0, 0, 0, time.UTC)
+
+	ri, err := ParseISORepeatingInterval("R3/2024-03-01T13:00:00Z/2024-03-01T15:30:00Z", time.UTC)
+	if err != nil || !ri.Start.Equal(start) || ri.Period != (ISOPeriod{Minutes: 150}) {
+		t.Log("Failed to parse start/end", ri, err)
+		t.Fail()
+	}
+
+	ri, err = ParseISORepeatingInterval("R2/P1D/2024-03-03T13:00:00Z", time.UTC)
+	if err != nil || !ri.Start.Equal(start) {
+		t.Log("Failed to parse period/end", ri, err)
+		t.Fail()
+	}
+
+	ri, err = ParseISORepeatingInterval("R/20240301T130000Z/PT1H", time.UTC)
+	if err != nil || ri.Repetitions != -1 {
+		t.Log("Failed to parse unbounded", ri, err)
+		t.Fail()
+	}
+}
+
+func Test_ISORepeatingInterval_Unsupported(t *testing.T) {
+	ri, _ := ParseISORepeatingInterval("R5/2024-03-01T13:00:00Z/P1Y2M10DT2H30M", time.UTC)
+	if _, err := ri.Rule(); err == nil {
+		t.Log("Mixed periods have no RRULE equivalent")
+		t.Fail()
+	}
+
+	ri, _ = ParseISORepeatingInterval("R5/2024-01-31T13:00:00Z/P1M", time.UTC)
+	if _, err := ri.Rule(); err == nil {
+		t.Log("Month end periods have no RRULE equivalent")
+		t.Fail()
+	}
+
+	rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,TU")
+	if _, err := rule.ISORepeatingInterval(); err == nil {
+		t.Log("BYDAY has no ISO equivalent")
+		t.Fail()
+	}
+}
+
+func Test_ISORepeatingInterval_Until(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=DAILY;UNTIL=19970905T090000Z")
+
+	ri, err := rule.ISORepeatingInterval()
+	if err != nil || ri.String() != "R4/1997-09-02T09:00:00Z/P1D" {
+		t.Log("Failed to convert UNTIL", ri, err)
+		t.Fail()
+	}
+}
+
+func Test_ISOIterator(t *testing.T) {
+	ri, _ := ParseISORepeatingInterval("R3/2024-01-31T13:00:00Z/P1M1DT2H", time.UTC)
+
+	expected := []time.Time{
+		time.Date(2024, 1, 31, 13, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 2, 17, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	var index int
+
+	iter := ri.Iterator()
+	for iter.Step(&event) {
+		if index >= len(expected) || !event.Equal(expected[index]) {
+			t.Log("Unexpected occurrence", event)
+			t.Fail()
+		}
+		index += 1
+	}
+
+	if iter.ReturnCounter != len(expected) {
+		t.Log("Failed to return enough results", iter.ReturnCounter)
+		t.Fail()
+	}
+
+	ri, _ = ParseISORepeatingInterval("R/2024-01-01T00:00:00Z/PT1H", time.UTC)
+	iter = ri.Iterator().After(time.Date(2024, 1, 1, 5, 30, 0, 0, time.UTC)).Limit(2)
+
+	iter.Step(&event)
+	if !event.Equal(time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Log("After failed", event)
+		t.Fail()
+	}
+
+	iter.Step(&event)
+	if iter.Step(&event) {
+		t.Log("Limit failed")
+		t.Fail()
+	}
+}
+
+func Test_ISOPeriod_MonthEnd(t *testing.T) {
+	// Months that are too short end on their last day, and the next month
+	// is back on the 31st.
+	ri, _ := ParseISORepeatingInterval("R4/2024-01-31T00:00:00Z/P1M", time.UTC)
+
+	expected := []time.Time{
+		time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	var index int
+	iter := ri.Iterator()
+	for iter.Step(&event) {
+		if index >= len(expected) || !event.Equal(expected[index]) {
+			t.Log("Unexpected occurrence", event)
+			t.Fail()
+		}
+		index += 1
+	}
+
+	period := ISOPeriod{Years: 1}
+	if leap := time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC); !period.AddTo(leap, 1).Equal(time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC)) {
+		t.Log("P1Y from a leap day", period.AddTo(leap, 1))
+		t.Fail()
+	}
+}
+
+func Test_ISORepeatingInterval_YearlyMonthEnd(t *testing.T) {
+	// Adding years only changes the day from February 29th.
+	ri, _ := ParseISORepeatingInterval("R3/2024-01-31T09:00:00Z/P1Y", time.UTC)
+	rule, err := ri.Rule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sameOccurrences(t, ri.Iterator(), rule.Iterator())
+
+	back, err := rule.ISORepeatingInterval()
+	if err != nil || back.String() != "R3/2024-01-31T09:00:00Z/P1Y" {
+		t.Log("Failed to convert back", back, err)
+		t.Fail()
+	}
+
+	ri, _ = ParseISORepeatingInterval("R3/2024-02-29T09:00:00Z/P1Y", time.UTC)
+	if _, err := ri.Rule(); err == nil {
+		t.Log("P1Y from February 29th has no RRULE equivalent")
+		t.Fail()
+	}
+}
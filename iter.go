@@ -1,25 +1,50 @@
 package rrule
 
 import (
+	"context"
 	"time"
 )
 
-// should use github.com/jinzhu/copier
-
-// Creating occurences of a recurrece rule is non-trivial because some
-// fields allow positional or negative selection. In some cases possibilities
-// are generated and the correct events are selected from them.
-// In other cases (specifically, hours, minutes, seconds) those times
-// are easier to generate.
+// RecurrenceIterator steps through the occurrences of a rule in order, a
+// period of the rule at a time, see expand.go.
 type RecurrenceIterator struct {
 	rule        *RecurringRule
+	expansion   *expansion
 	iterCounter int
 	iterBuffer  []time.Time
-
+	buffer      []time.Time
+
+	// periods counts the periods looked at, for HardLimit.
+	periods int
+	started bool
+
+	// counting is set by StepOccurrence so After doesn't skip the periods
+	// before it, seeked when it did.
+	counting bool
+	seeked   bool
+
+	// The first and last occurrences of the rule, for StepOccurrence.
+	endsFound bool
+	first     time.Time
+	final     time.Time
+
+	// reverse is set by Reverse, last is the latest occurrence the rule
+	// allows and lowest the first period that can be after UserAfter.
+	reverse bool
+	last    time.Time
+	lowest  int
+
+	// ReturnCounter is how many occurrences Step returned, series how many
+	// of the rule there are up to the last one in iterBuffer, for COUNT.
 	ReturnCounter int
+	series        int
 
 	UserLimit int
 
+	// resumed is ReturnCounter when the iterator was resumed from a
+	// cursor, Limit counts from it.
+	resumed int
+
 	UserBefore time.Time
 	UserAfter  time.Time
 
@@ -28,6 +53,9 @@ type RecurrenceIterator struct {
 
 	hardLimit          int
 	isHardLimitReached bool
+
+	ctx context.Context
+	err error
 }
 
 func (ri *RecurrenceIterator) Limit(i int) *RecurrenceIterator {
@@ -37,7 +65,8 @@ func (ri *RecurrenceIterator) Limit(i int) *RecurrenceIterator {
 
 // HardLimit is used to set a limit to iteration count.
 // If iterator tooks more steps than provided limit, it will return false
-// and IsHardLimitReached() == true.
+// and IsHardLimitReached() == true. Periods skipped by After or because
+// they can't have occurrences aren't counted.
 func (ri *RecurrenceIterator) HardLimit(limit int) *RecurrenceIterator {
 	ri.hardLimit = limit
 	return ri
@@ -47,12 +76,44 @@ func (ri *RecurrenceIterator) IsHardLimitReached() bool {
 	return ri.isHardLimitReached
 }
 
+// Context stops the iteration when ctx is done, Step returns false and Err
+// the reason. It's checked for every period, so a rule that goes a long
+// way between occurrences stops too.
+func (ri *RecurrenceIterator) Context(ctx context.Context) *RecurrenceIterator {
+	ri.ctx = ctx
+	return ri
+}
+
+// Err is the error of the context that stopped the iteration, if it was
+// stopped by one.
+func (ri *RecurrenceIterator) Err() error {
+	return ri.err
+}
+
+// stopped is true when HardLimit or the context stop the iteration before
+// another period.
+func (ri *RecurrenceIterator) stopped() bool {
+	if ri.hardLimit > 0 && ri.periods > ri.hardLimit {
+		ri.isHardLimitReached = true
+		return true
+	}
+	if ri.ctx != nil && ri.ctx.Err() != nil {
+		ri.err = ri.ctx.Err()
+		return true
+	}
+	return false
+}
+
 func (ri *RecurrenceIterator) Before(b time.Time) *RecurrenceIterator {
 	ri.UserBefore = b
 	ri.UseUserBefore = true
 	return ri
 }
 
+// After starts the iteration at the period b is in, rather than stepping
+// through the ones before it. Rules with a COUNT are still stepped through
+// from DTSTART, as the occurrences before b count toward it, but periods
+// that can't have occurrences are skipped.
 func (ri *RecurrenceIterator) After(b time.Time) *RecurrenceIterator {
 	ri.UserAfter = b
 	ri.UseUserAfter = true
@@ -65,424 +126,66 @@ func (ri *RecurrenceIterator) Between(a, b time.Time) *RecurrenceIterator {
 	return ri
 }
 
-func (ri *RecurrenceIterator) generateCandidates(root time.Time) []time.Time {
-	var results []time.Time
-
-	switch ri.rule.Frequency {
-	case YEARLY:
-		for m := time.January; m < 13; m += 1 {
-			daysInMonth := time.Date(root.Year(), time.Month(m)+1, 0, 0, 0, 0, 0, time.UTC).Day()
-			for d := 1; d <= daysInMonth; d += 1 {
-				var target time.Time = time.Date(
-					root.Year(),
-					time.Month(m),
-					d,
-					root.Hour(),
-					root.Minute(),
-					root.Second(),
-					0,
-					root.Location(),
-				)
-
-				results = append(results, target)
-			}
-		}
-
-	case MONTHLY:
-		daysInMonth := time.Date(root.Year(), root.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
-		for d := 1; d <= daysInMonth; d += 1 {
-			var target time.Time = time.Date(
-				root.Year(),
-				root.Month(),
-				d,
-				root.Hour(),
-				root.Minute(),
-				root.Second(),
-				0,
-				root.Location(),
-			)
-
-			results = append(results, target)
-		}
-	case WEEKLY:
-		t := time.Date(root.Year(), root.Month(), root.Day(), root.Hour(), root.Minute(), root.Second(), 0, root.Location())
-
-		// If the week start is non-standard
-		// walk back until we find it.
-		for t.Weekday() != ri.rule.WorkWeekStart {
-			t = t.AddDate(0, 0, -1)
-		}
-
-		for d := 0; d < 7; d += 1 {
-			results = append(results, t)
-			t = t.AddDate(0, 0, 1)
-		}
-
-	case DAILY:
-		fallthrough
-	case HOURLY:
-		fallthrough
-	case MINUTELY:
-		fallthrough
-	case SECONDLY:
-		results = append(results, ri.generateTimesForCandidates(root)...)
-	default:
-		panic("Unknown frequency")
-	}
-
-	return results
-}
-
-func (ri *RecurrenceIterator) generateTimesForCandidates(root time.Time) []time.Time {
-	var results []time.Time
-
-	var ByHourItems []int16 = []int16{int16(root.Hour())}
-	var ByMinuteItems []int16 = []int16{int16(root.Minute())}
-	var BySecondItems []int16 = []int16{int16(root.Second())}
-
-	if len(ri.rule.ByHour) > 0 && (ri.rule.Frequency <= DAILY) {
-		ByHourItems = ri.rule.ByHour
+func (ri *RecurrenceIterator) Step(t *time.Time) bool {
+	// Checked before taking the next occurrence, so it's still there for
+	// a Cursor.
+	if ri.UserLimit > 0 && ri.ReturnCounter-ri.resumed >= ri.UserLimit {
+		return false
 	}
 
-	if len(ri.rule.ByMinute) > 0 && (ri.rule.Frequency <= MINUTELY) {
-		ByMinuteItems = ri.rule.ByMinute
+	if ri.reverse {
+		return ri.stepBack(t)
 	}
-
-	if len(ri.rule.BySecond) > 0 && (ri.rule.Frequency <= SECONDLY) {
-		BySecondItems = ri.rule.BySecond
+	if ri.rule.Count > 0 && ri.series >= ri.rule.Count && len(ri.iterBuffer) == 0 {
+		return false
 	}
 
-	for _, h := range ByHourItems {
-		for _, m := range ByMinuteItems {
-			for _, s := range BySecondItems {
-				results = append(
-					results,
-					time.Date(
-						root.Year(),
-						root.Month(),
-						root.Day(),
-						int(h),
-						int(m),
-						int(s),
-						0,
-						root.Location(),
-					),
-				)
-			}
+	if !ri.started {
+		ri.started = true
+		if ri.UseUserAfter && ri.rule.Count == 0 && !ri.counting {
+			ri.iterCounter = ri.expansion.periodOf(ri.UserAfter)
+			ri.seeked = true
 		}
 	}
 
-	return results
-}
-
-func (ri *RecurrenceIterator) Step(t *time.Time) bool {
-	if ri.rule.Count > 0 && ri.ReturnCounter >= ri.rule.Count {
-		return false
-	}
-
 	var shortcircuitFinish bool = false
 
 	for len(ri.iterBuffer) == 0 && shortcircuitFinish == false {
-		// Exit if we reached hard limit
-		if ri.hardLimit > 0 && ri.iterCounter > ri.hardLimit {
-			ri.isHardLimitReached = true
+		if ri.stopped() {
 			return false
 		}
 
-		// If we've made it here, we need to generate more results
-		next_base := getNextDateByFreqAndInterval(
-			ri.rule.DtStart,
-			ri.rule.Frequency,
-			ri.rule.Interval*ri.iterCounter,
-		)
-
-		candidates := ri.generateCandidates(
-			next_base,
-		)
-
-		candidate_count := len(candidates)
-
-		final_set := []time.Time{}
-
-		for cindex, d := range candidates {
-			var matches []bool
-
-			if len(ri.rule.ByYearDay) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByYearDay {
-					if value > 0 {
-						if d.YearDay() == int(value) {
-							match = true
-						}
-					} else if value < 0 {
-						if ((candidate_count - cindex) + int(value)) == 0 {
-							match = true
-						}
-					}
-				}
-				matches = append(matches, match)
+		ri.iterBuffer = ri.buffer[:0]
+		for _, d := range ri.expansion.period(ri.iterCounter) {
+			if ri.UseUserBefore && !d.Before(ri.UserBefore) {
+				// Since we can only generate events after this one
+				// we are done.
+				shortcircuitFinish = true
+				break
 			}
 
-			if len(ri.rule.ByMonthDay) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByMonthDay {
-					if value > 0 {
-						if d.Day() == int(value) {
-							match = true
-						}
-					} else if value < 0 {
-						if ((candidate_count - cindex) + int(value)) == 0 {
-							match = true
-						}
-					}
-				}
-				matches = append(matches, match)
+			if d.Before(ri.rule.DtStart) || ri.isException(d) {
+				continue
 			}
 
-			if len(ri.rule.ByMonth) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByMonth {
-					if value > 0 {
-						if d.Month() == time.Month(value) {
-							match = true
-						}
-					} else if value < 0 {
-						if ((candidate_count - cindex) + int(value)) == 0 {
-							match = true
-						}
-					}
-				}
-				matches = append(matches, match)
+			// COUNT is of the whole series, the occurrences before
+			// After count too.
+			if ri.rule.Count > 0 && ri.series >= ri.rule.Count {
+				shortcircuitFinish = true
+				break
 			}
+			ri.series += 1
 
-			if len(ri.rule.ByWeekNo) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByWeekNo {
-					_, weeknum := d.ISOWeek()
-					if value > 0 {
-						if weeknum == int(value) {
-							match = true
-						}
-					} else if value < 0 {
-						if ((candidate_count - cindex) + int(value)) == 0 {
-							match = true
-						}
-					}
-				}
-
-				matches = append(matches, match)
-			}
-
-			// We can't actually check the ByDay until we've evaluated
-			// all of the candidates. But we'll filter here, to make
-			// sure that there is less work in the future.
-
-			if len(ri.rule.ByDay) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByDay {
-					if value.Weekday == d.Weekday() {
-						if value.Offset == 0 {
-							// match every occurence of a DOW
-							match = true
-						} else if value.Offset > 0 {
-							// the first or Nth occurence
-							if (cindex/7)+1 == value.Offset {
-								match = true
-							}
-						} else if value.Offset < 0 {
-							// the last or Nth last occurence
-							cc_offset := ((candidate_count - cindex) - 1) / 7
-							if cc_offset+(value.Offset+1) == 0 {
-								match = true
-							}
-						}
-					}
-				}
-				matches = append(matches, match)
-			}
-
-			// ByHour, ByMinute and BySecond don't support negative/relative
-			// indexes, so the comparison is significanly simpler.
-
-			if len(ri.rule.ByHour) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByHour {
-					if int(value) == d.Hour() {
-						match = true
-					}
-				}
-
-				matches = append(matches, match)
+			if ri.UseUserAfter && !d.After(ri.UserAfter) {
+				continue
 			}
 
-			if len(ri.rule.ByMinute) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.ByMinute {
-					if int(value) == d.Minute() {
-						match = true
-					}
-				}
-
-				matches = append(matches, match)
-			}
-
-			if len(ri.rule.BySecond) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.BySecond {
-					if int(value) == d.Second() {
-						match = true
-					}
-				}
-
-				matches = append(matches, match)
-			}
-
-			// For a better understanding of some of the interesting
-			// if | else if  cases below, take a look at
-			// https://tools.ietf.org/html/rfc5545#page-44
-			// there are some special cases to be aware of that
-			// are not immediately obvious.
-
-			if len(ri.rule.ByMonthDay) == 0 && ri.rule.Frequency == MONTHLY {
-				if d.Day() == ri.rule.DtStart.Day() {
-					matches = append(matches, true)
-				}
-			}
-
-			if len(ri.rule.ByDay) == 0 && ri.rule.Frequency == WEEKLY {
-				if d.Weekday() == ri.rule.DtStart.Weekday() {
-					matches = append(matches, true)
-				}
-			}
-
-			if len(ri.rule.ByHour) == 0 && ri.rule.Frequency == DAILY {
-				if d.Hour() == ri.rule.DtStart.Hour() {
-					matches = append(matches, true)
-				}
-			}
-
-			if len(ri.rule.ByMinute) == 0 && ri.rule.Frequency == HOURLY {
-				if d.Minute() == ri.rule.DtStart.Minute() {
-					matches = append(matches, true)
-				}
-			}
-
-			if len(ri.rule.BySecond) == 0 && ri.rule.Frequency == MINUTELY {
-				if d.Second() == ri.rule.DtStart.Second() {
-					matches = append(matches, true)
-				}
-			}
-
-			// This seems like cheating, and it works because effectively
-			// every timestamp modified by seconds should work in this scenario.
-			// The logic however, doesn't seem super clear, so this should be
-			// rewritten in a way that isn't so:
-			//
-			//       "i dont know why it works, but it works."
-			//
-			// Secondly isn't tested aggressively via the RFC, and I doubt
-			// the recurrence rules are generally used with seconds.
-			if ri.rule.Frequency == SECONDLY {
-				matches = append(matches, true)
-			}
-
-			if len(ri.rule.ByYearDay) == 0 && len(ri.rule.ByMonthDay) == 0 &&
-				len(ri.rule.ByDay) == 0 && ri.rule.Frequency == YEARLY {
-
-				var match bool = false
-
-				if len(ri.rule.ByMonth) == 0 {
-					if d.YearDay() == ri.rule.DtStart.YearDay() {
-						match = true
-					}
-				} else if d.Day() == ri.rule.DtStart.Day() {
-					match = true
-				}
-
-				matches = append(matches, match)
-			}
-
-			var final_match bool = true
-
-			if len(matches) == 0 {
-				final_match = false
-			} else {
-				for _, m := range matches {
-					final_match = final_match && m
-				}
-			}
-
-			if final_match {
-				final_set = append(final_set, d)
-			}
+			ri.iterBuffer = append(ri.iterBuffer, d)
 		}
+		ri.buffer = ri.iterBuffer
 
-		// Once we've generated the set of candidates that pass
-		// the rules defined by the user, bysetpos can select from
-		// that set to determine the final set.
-
-		final_candidate_count := len(final_set)
-
-		for cindex, d := range final_set {
-			var matches []bool
-
-			if len(ri.rule.BySetPos) > 0 {
-				var match bool = false
-				for _, value := range ri.rule.BySetPos {
-					if value > 0 {
-						if cindex == int(value-1) {
-							match = true
-						}
-					} else if value < 0 {
-						fcc := (final_candidate_count - cindex) + int(value)
-						if fcc == 0 {
-							match = true
-						}
-					}
-				}
-
-				matches = append(matches, match)
-			}
-
-			for _, exDate := range ri.rule.ExceptionsToRule {
-				if exDate.Equal(d) {
-					matches = append(matches, false)
-				}
-			}
-
-			if ri.UseUserBefore {
-				if d.Before(ri.UserBefore) {
-					matches = append(matches, true)
-				} else {
-					matches = append(matches, false)
-					// Since we can only generate events after this one
-					// we are done.
-					shortcircuitFinish = true
-				}
-			}
-
-			if ri.UseUserAfter {
-				if d.After(ri.UserAfter) {
-					matches = append(matches, true)
-				} else {
-					matches = append(matches, false)
-				}
-			}
-
-			var final_match bool = true
-
-			if len(matches) > 0 {
-				for _, m := range matches {
-					final_match = final_match && m
-				}
-			}
-
-			if final_match && !d.Before(ri.rule.DtStart) {
-				ri.iterBuffer = append(ri.iterBuffer, d)
-			}
-		}
-
-		ri.iterCounter += 1
+		ri.iterCounter = ri.expansion.next(ri.iterCounter)
+		ri.periods += 1
 	}
 
 	if len(ri.iterBuffer) > 0 {
@@ -494,42 +197,17 @@ func (ri *RecurrenceIterator) Step(t *time.Time) bool {
 		}
 
 		ri.ReturnCounter += 1
-
-		if ri.UserLimit > 0 && ri.ReturnCounter > ri.UserLimit {
-			return false
-		}
 		return true
 	}
 
 	return !shortcircuitFinish
 }
 
-func getNextDateByFreqAndInterval(last time.Time, freq FrequencyValue, interval int) time.Time {
-	switch freq {
-	case YEARLY:
-		// In order to avoid the double month increase on shorter months.
-		last = time.Date(last.Year(), last.Month(), 1,
-			last.Hour(), last.Minute(), last.Second(),
-			0, last.Location())
-
-		last = last.AddDate(1*interval, 0, 0)
-	case MONTHLY:
-		// In order to avoid the double month increase on shorter months.
-		last = time.Date(last.Year(), last.Month(), 1,
-			last.Hour(), last.Minute(), last.Second(),
-			0, last.Location())
-
-		last = last.AddDate(0, 1*interval, 0)
-	case WEEKLY:
-		last = last.AddDate(0, 0, 7*interval)
-	case DAILY:
-		last = last.AddDate(0, 0, 1*interval)
-	case HOURLY:
-		last = last.Add(time.Hour * time.Duration(interval))
-	case MINUTELY:
-		last = last.Add(time.Minute * time.Duration(interval))
-	case SECONDLY:
-		last = last.Add(time.Second * time.Duration(interval))
+func (ri *RecurrenceIterator) isException(d time.Time) bool {
+	for _, exDate := range ri.rule.ExceptionsToRule {
+		if exDate.Equal(d) {
+			return true
+		}
 	}
-	return last
+	return false
 }
@@ -0,0 +1,295 @@
+package rrule
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSCalendar (https://tools.ietf.org/html/rfc8984) describes recurrences
+// as JSON objects rather than RRULE strings. The types below mirror the
+// recurrence related properties of a JSCalendar Event so that they can be
+// used directly with encoding/json.
+
+// JSLocalDateTimeFormat is the LocalDateTime layout used by JSCalendar.
+const JSLocalDateTimeFormat = "2006-01-02T15:04:05"
+
+// https://tools.ietf.org/html/rfc8984#section-4.3.3
+type JSNDay struct {
+	Type        string `json:"@type,omitempty"`
+	Day         string `json:"day"`
+	NthOfPeriod int    `json:"nthOfPeriod,omitempty"`
+}
+
+// https://tools.ietf.org/html/rfc8984#section-4.3.3
+type JSRecurrenceRule struct {
+	Type           string   `json:"@type,omitempty"`
+	Frequency      string   `json:"frequency"`
+	Interval       int      `json:"interval,omitempty"`
+	Rscale         string   `json:"rscale,omitempty"`
+	Skip           string   `json:"skip,omitempty"`
+	FirstDayOfWeek string   `json:"firstDayOfWeek,omitempty"`
+	ByDay          []JSNDay `json:"byDay,omitempty"`
+	ByMonthDay     []int    `json:"byMonthDay,omitempty"`
+	ByMonth        []string `json:"byMonth,omitempty"`
+	ByYearDay      []int    `json:"byYearDay,omitempty"`
+	ByWeekNo       []int    `json:"byWeekNo,omitempty"`
+	ByHour         []int    `json:"byHour,omitempty"`
+	ByMinute       []int    `json:"byMinute,omitempty"`
+	BySecond       []int    `json:"bySecond,omitempty"`
+	BySetPosition  []int    `json:"bySetPosition,omitempty"`
+	Count          int      `json:"count,omitempty"`
+	Until          string   `json:"until,omitempty"`
+}
+
+// JSRecurrence holds the recurrence related properties of a JSCalendar
+// Event. Start is a LocalDateTime, interpreted in TimeZone. An empty
+// TimeZone is a floating time, which maps to time.Local.
+type JSRecurrence struct {
+	Start                   string                            `json:"start"`
+	TimeZone                string                            `json:"timeZone,omitempty"`
+	RecurrenceRules         []JSRecurrenceRule                `json:"recurrenceRules,omitempty"`
+	ExcludedRecurrenceRules []JSRecurrenceRule                `json:"excludedRecurrenceRules,omitempty"`
+	RecurrenceOverrides     map[string]map[string]interface{} `json:"recurrenceOverrides,omitempty"`
+}
+
+func jsDayFromWeekday(d time.Weekday) string {
+	return strings.ToLower(shortFromWeekday(d))
+}
+
+func weekdayFromJSDay(s string) (time.Weekday, bool) {
+	switch s {
+	case "su", "mo", "tu", "we", "th", "fr", "sa":
+		return weekdayFromShort(strings.ToUpper(s)), true
+	}
+	return time.Sunday, false
+}
+
+func jsLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(timeZone)
+}
+
+func intsToInt16(values []int) []int16 {
+	var results []int16
+	for _, v := range values {
+		results = append(results, int16(v))
+	}
+	return results
+}
+
+func int16sToInt(values []int16) []int {
+	var results []int
+	for _, v := range values {
+		results = append(results, int(v))
+	}
+	return results
+}
+
+// RuleFromJSCalendar converts the recurrence properties of a JSCalendar
+// Event into a RecurringRule. Exactly one entry in recurrenceRules is
+// supported. Overrides that only exclude an instance become
+// ExceptionsToRule, any other override is reported as unsupported.
+func RuleFromJSCalendar(jr *JSRecurrence) (*RecurringRule, error) {
+	ce := &ConversionError{Format: "JSCalendar"}
+
+	rr := &RecurringRule{
+		Interval:      1,
+		WorkWeekStart: time.Monday,
+	}
+
+	loc, err := jsLocation(jr.TimeZone)
+	if err != nil {
+		ce.Add("unknown timeZone %q", jr.TimeZone)
+		return rr, ce
+	}
+
+	start, err := time.ParseInLocation(JSLocalDateTimeFormat, jr.Start, loc)
+	if err != nil {
+		ce.Add("invalid start %q", jr.Start)
+	}
+	rr.DtStart = start
+
+	if len(jr.RecurrenceRules) != 1 {
+		ce.Add("exactly one recurrenceRule is supported, got %d", len(jr.RecurrenceRules))
+	}
+
+	if len(jr.ExcludedRecurrenceRules) > 0 {
+		ce.Add("excludedRecurrenceRules can not be represented")
+	}
+
+	if len(jr.RecurrenceRules) > 0 {
+		jsRuleInto(rr, &jr.RecurrenceRules[0], loc, ce)
+	}
+
+	var keys []string
+	for key := range jr.RecurrenceOverrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		patch := jr.RecurrenceOverrides[key]
+		if excluded, ok := patch["excluded"].(bool); !ok || !excluded || len(patch) != 1 {
+			ce.Add("recurrenceOverride %s only supports {\"excluded\": true}", key)
+			continue
+		}
+
+		exDate, err := time.ParseInLocation(JSLocalDateTimeFormat, key, loc)
+		if err != nil {
+			ce.Add("invalid recurrenceOverride key %q", key)
+			continue
+		}
+		rr.ExceptionsToRule = append(rr.ExceptionsToRule, exDate)
+	}
+
+	if err := ce.Err(); err != nil {
+		return rr, err
+	}
+
+	return rr, rr.internal_parser()
+}
+
+func jsRuleInto(rr *RecurringRule, js *JSRecurrenceRule, loc *time.Location, ce *ConversionError) {
+	if err := rr.handle_part_freq(strings.ToUpper(js.Frequency)); err != nil {
+		ce.Add("unknown frequency %q", js.Frequency)
+	}
+
+	if js.Rscale != "" && js.Rscale != "gregorian" {
+		ce.Add("rscale %q is not supported, only gregorian", js.Rscale)
+	}
+
+	// RRULE always omits invalid dates, which is the JSCalendar default.
+	if js.Skip != "" && js.Skip != "omit" {
+		ce.Add("skip %q can not be represented", js.Skip)
+	}
+
+	if js.Interval > 0 {
+		rr.Interval = js.Interval
+	}
+
+	if js.FirstDayOfWeek != "" {
+		if wd, ok := weekdayFromJSDay(js.FirstDayOfWeek); ok {
+			rr.WorkWeekStart = wd
+		} else {
+			ce.Add("invalid firstDayOfWeek %q", js.FirstDayOfWeek)
+		}
+	}
+
+	for _, nd := range js.ByDay {
+		wd, ok := weekdayFromJSDay(nd.Day)
+		if !ok {
+			ce.Add("invalid byDay day %q", nd.Day)
+			continue
+		}
+		rr.ByDay = append(rr.ByDay, ForDay{Weekday: wd, Offset: nd.NthOfPeriod})
+	}
+
+	for _, month := range js.ByMonth {
+		if strings.HasSuffix(month, "L") {
+			ce.Add("leap month %q requires a non-gregorian rscale", month)
+			continue
+		}
+		iv, err := strconv.ParseInt(month, 10, 16)
+		if err != nil || iv < 1 || iv > 12 {
+			ce.Add("invalid byMonth %q", month)
+			continue
+		}
+		rr.ByMonth = append(rr.ByMonth, int16(iv))
+	}
+
+	rr.ByMonthDay = intsToInt16(js.ByMonthDay)
+	rr.ByYearDay = intsToInt16(js.ByYearDay)
+	rr.ByWeekNo = intsToInt16(js.ByWeekNo)
+	rr.ByHour = intsToInt16(js.ByHour)
+	rr.ByMinute = intsToInt16(js.ByMinute)
+	rr.BySecond = intsToInt16(js.BySecond)
+	rr.BySetPos = intsToInt16(js.BySetPosition)
+	rr.Count = js.Count
+
+	if js.Until != "" {
+		until, err := time.ParseInLocation(JSLocalDateTimeFormat, js.Until, loc)
+		if err != nil {
+			ce.Add("invalid until %q", js.Until)
+		}
+		rr.Until = until
+	}
+}
+
+// JSCalendar converts the rule into the recurrence properties of a
+// JSCalendar Event. A DtStart in time.Local is written as a floating time
+// (no timeZone), every other location must be a named IANA zone.
+func (rr *RecurringRule) JSCalendar() (*JSRecurrence, error) {
+	ce := &ConversionError{Format: "JSCalendar"}
+	jr := &JSRecurrence{}
+
+	if rr.DtStart.Equal(EmptyTime) {
+		ce.Add("DtStart is required")
+	}
+
+	loc := rr.DtStart.Location()
+
+	if loc != time.Local {
+		jr.TimeZone = loc.String()
+		if loc == time.UTC {
+			jr.TimeZone = "Etc/UTC"
+		} else if _, err := time.LoadLocation(jr.TimeZone); err != nil {
+			ce.Add("location %q is not an IANA time zone", jr.TimeZone)
+		}
+	}
+
+	jr.Start = rr.DtStart.Format(JSLocalDateTimeFormat)
+
+	js := JSRecurrenceRule{
+		Type:      "RecurrenceRule",
+		Frequency: strings.ToLower(rr.Frequency.String()),
+	}
+
+	if rr.Interval > 1 {
+		js.Interval = rr.Interval
+	}
+
+	if rr.WorkWeekStart != time.Monday {
+		js.FirstDayOfWeek = jsDayFromWeekday(rr.WorkWeekStart)
+	}
+
+	for _, fd := range rr.ByDay {
+		js.ByDay = append(js.ByDay, JSNDay{
+			Type:        "NDay",
+			Day:         jsDayFromWeekday(fd.Weekday),
+			NthOfPeriod: fd.Offset,
+		})
+	}
+
+	for _, month := range rr.ByMonth {
+		js.ByMonth = append(js.ByMonth, strconv.Itoa(int(month)))
+	}
+
+	js.ByMonthDay = int16sToInt(rr.ByMonthDay)
+	js.ByYearDay = int16sToInt(rr.ByYearDay)
+	js.ByWeekNo = int16sToInt(rr.ByWeekNo)
+	js.ByHour = int16sToInt(rr.ByHour)
+	js.ByMinute = int16sToInt(rr.ByMinute)
+	js.BySecond = int16sToInt(rr.BySecond)
+	js.BySetPosition = int16sToInt(rr.BySetPos)
+	js.Count = rr.Count
+
+	if !rr.Until.Equal(EmptyTime) {
+		js.Until = rr.Until.In(loc).Format(JSLocalDateTimeFormat)
+	}
+
+	jr.RecurrenceRules = []JSRecurrenceRule{js}
+
+	for _, exDate := range rr.ExceptionsToRule {
+		if jr.RecurrenceOverrides == nil {
+			jr.RecurrenceOverrides = map[string]map[string]interface{}{}
+		}
+		key := exDate.In(loc).Format(JSLocalDateTimeFormat)
+		jr.RecurrenceOverrides[key] = map[string]interface{}{"excluded": true}
+	}
+
+	return jr, ce.Err()
+}
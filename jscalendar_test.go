@@ -0,0 +1,143 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func Test_JSCalendar_Decode(t *testing.T) {
+	var value = `{
+		"start": "1997-09-02T09:00:00",
+		"timeZone": "America/New_York",
+		"recurrenceRules": [{
+			"@type": "RecurrenceRule",
+			"frequency": "monthly",
+			"interval": 2,
+			"firstDayOfWeek": "su",
+			"byDay": [{"@type": "NDay", "day": "fr", "nthOfPeriod": -1}],
+			"until": "1997-12-24T00:00:00"
+		}],
+		"recurrenceOverrides": {
+			"1997-10-31T09:00:00": {"excluded": true}
+		}
+	}`
+
+	var jr JSRecurrence
+	if err := json.Unmarshal([]byte(value), &jr); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := RuleFromJSCalendar(&jr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"EXDATE;TZID=America/New_York:19971031T090000\n" +
+			"RRULE:FREQ=MONTHLY;INTERVAL=2;WKST=SU;BYDAY=-1FR;UNTIL=19971224T000000",
+	)
+
+	if !rule.Equal(expected) {
+		t.Log("Rules don't match", rule, expected)
+		t.Fail()
+	}
+}
+
+func Test_JSCalendar_RoundTrip(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"EXDATE;TZID=America/New_York:19970904T090000\n" +
+			"RRULE:FREQ=YEARLY;COUNT=10;BYMONTH=1,3;BYDAY=2TU,TH;BYSETPOS=1,-1",
+	)
+
+	jr, err := rule.JSCalendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jr.TimeZone != "America/New_York" || jr.Start != "1997-09-02T09:00:00" {
+		t.Log("Bad start", jr.TimeZone, jr.Start)
+		t.Fail()
+	}
+
+	encoded, _ := json.Marshal(jr)
+
+	var decoded JSRecurrence
+	json.Unmarshal(encoded, &decoded)
+
+	result, err := RuleFromJSCalendar(&decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Equal(rule) {
+		t.Log("Round trip failed", string(encoded))
+		t.Fail()
+	}
+}
+
+func Test_JSCalendar_Floating(t *testing.T) {
+	rule := &RecurringRule{
+		DtStart:       time.Date(2024, 3, 1, 8, 30, 0, 0, time.Local),
+		Frequency:     DAILY,
+		Interval:      1,
+		WorkWeekStart: time.Monday,
+	}
+
+	jr, err := rule.JSCalendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jr.TimeZone != "" {
+		t.Log("Floating time should not have a timeZone", jr.TimeZone)
+		t.Fail()
+	}
+
+	result, _ := RuleFromJSCalendar(jr)
+	if result.DtStart.Location() != time.Local || !result.DtStart.Equal(rule.DtStart) {
+		t.Log("Floating start not preserved", result.DtStart)
+		t.Fail()
+	}
+}
+
+func Test_JSCalendar_Unsupported(t *testing.T) {
+	jr := &JSRecurrence{
+		Start: "2024-01-01T00:00:00",
+		RecurrenceRules: []JSRecurrenceRule{{
+			Frequency: "yearly",
+			Rscale:    "chinese",
+			Skip:      "forward",
+			ByMonth:   []string{"5L"},
+		}},
+		ExcludedRecurrenceRules: []JSRecurrenceRule{{Frequency: "weekly"}},
+		RecurrenceOverrides: map[string]map[string]interface{}{
+			"2024-02-01T00:00:00": {"title": "moved"},
+		},
+	}
+
+	_, err := RuleFromJSCalendar(jr)
+
+	ce, ok := err.(*ConversionError)
+	if !ok {
+		t.Fatal("Expected a ConversionError", err)
+	}
+
+	if len(ce.Problems) != 5 {
+		t.Log("Expected every problem to be reported", ce.Problems)
+		t.Fail()
+	}
+
+	rule := &RecurringRule{
+		DtStart:   time.Date(2024, 3, 1, 8, 30, 0, 0, time.FixedZone("X", 3600)),
+		Frequency: DAILY,
+		Interval:  1,
+	}
+
+	if _, err := rule.JSCalendar(); err == nil {
+		t.Log("Fixed zones can't be represented")
+		t.Fail()
+	}
+}
@@ -5,6 +5,10 @@
 //
 //	rule, err := rrule.Parse("RRULE:FREQ=DAILY;COUNT=10")
 //
+// Rules can also be built in code, with the same defaults as Parse.
+//
+//	rule, err := rrule.Daily().From(start).Count(10).Build()
+//
 // Access to the occurences of a rule is via the Iterator struct.
 //
 //	iter := rule.Iterator()
@@ -16,8 +20,8 @@
 // Step will return false when you more results. Some events
 // recur forever, so be careful to ensure your loop will end.
 //
-// Iterators have a DtStart field, (which can also be parsed,
-// via the parse command. Setting the DtStart manually is allowed
+// Rules have a DtStart field, (which can also be parsed,
+// via the parse command. Setting the DtStart with WithDtStart is allowed
 // and often required if that data is stored separately
 // (Google Calendar for example).
 //
@@ -130,6 +134,8 @@ func (fd ForDay) String() string {
 	}
 }
 
+// RecurringRule can be shared between goroutines as long as it isn't
+// changed, use Clone or the With methods to derive a new rule instead.
 type RecurringRule struct {
 	DtStart time.Time
 
@@ -152,6 +158,47 @@ type RecurringRule struct {
 	ExceptionsToRule []time.Time
 }
 
+// Clone returns a deep copy of the rule that doesn't share any slices
+// with the original.
+func (rr *RecurringRule) Clone() *RecurringRule {
+	result := *rr
+
+	result.BySecond = append([]int16(nil), rr.BySecond...)
+	result.ByMinute = append([]int16(nil), rr.ByMinute...)
+	result.ByHour = append([]int16(nil), rr.ByHour...)
+	result.ByDay = append([]ForDay(nil), rr.ByDay...)
+	result.ByMonthDay = append([]int16(nil), rr.ByMonthDay...)
+	result.ByYearDay = append([]int16(nil), rr.ByYearDay...)
+	result.ByWeekNo = append([]int16(nil), rr.ByWeekNo...)
+	result.ByMonth = append([]int16(nil), rr.ByMonth...)
+	result.BySetPos = append([]int16(nil), rr.BySetPos...)
+	result.ExceptionsToRule = append([]time.Time(nil), rr.ExceptionsToRule...)
+
+	return &result
+}
+
+// WithDtStart returns a copy of the rule starting at start.
+func (rr *RecurringRule) WithDtStart(start time.Time) *RecurringRule {
+	result := rr.Clone()
+	result.DtStart = start
+	return result
+}
+
+// WithUntil returns a copy of the rule ending at until, use EmptyTime to
+// remove the end.
+func (rr *RecurringRule) WithUntil(until time.Time) *RecurringRule {
+	result := rr.Clone()
+	result.Until = until
+	return result
+}
+
+// WithExceptions returns a copy of the rule with dates as its only EXDATEs.
+func (rr *RecurringRule) WithExceptions(dates ...time.Time) *RecurringRule {
+	result := rr.Clone()
+	result.ExceptionsToRule = append([]time.Time(nil), dates...)
+	return result
+}
+
 func compareListsOfInt16(a, b []int16) bool {
 	if len(a) != len(b) {
 		return false
@@ -221,6 +268,10 @@ func (r1 *RecurringRule) Equal(r2 *RecurringRule) bool {
 		return false
 	}
 
+	if compareListsOfInt16(r1.ByMonth, r2.ByMonth) == false {
+		return false
+	}
+
 	if compareListsOfInt16(r1.BySetPos, r2.BySetPos) == false {
 		return false
 	}
@@ -441,8 +492,11 @@ func (rr *RecurringRule) handle_rule_chunk(value string) error {
 	return nil
 }
 
+// Iterator returns an iterator over a snapshot of the rule, changing the
+// rule afterwards doesn't affect it.
 func (rr *RecurringRule) Iterator() *RecurrenceIterator {
-	return &RecurrenceIterator{rule: rr, hardLimit: -1}
+	rule := rr.Clone()
+	return &RecurrenceIterator{rule: rule, expansion: newExpansion(rule), hardLimit: -1}
 }
 
 func listOfIntsToCSV(values []int16) string {
@@ -0,0 +1,612 @@
+package rrule
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Outlook stores the recurrence of an appointment in PidLidAppointmentRecur
+// as an AppointmentRecurrencePattern, see [MS-OXOCAL] section 2.2.1.44.
+// Every date in it is a local time counted in minutes since 1601-01-01,
+// the time zone is stored in other properties.
+
+const (
+	mapiFrequencyDaily   = 0x200A
+	mapiFrequencyWeekly  = 0x200B
+	mapiFrequencyMonthly = 0x200C
+	mapiFrequencyYearly  = 0x200D
+
+	mapiPatternDay      = 0x0000
+	mapiPatternWeek     = 0x0001
+	mapiPatternMonth    = 0x0002
+	mapiPatternMonthNth = 0x0003
+	mapiPatternMonthEnd = 0x0004
+
+	mapiEndAfterDate  = 0x2021
+	mapiEndAfterCount = 0x2022
+	mapiNeverEnd      = 0x2023
+
+	// The EndDate written for recurrences that never end, 31 Dec 4500.
+	mapiNoEndDate = 0x5AE980DF
+
+	mapiOverrideSubject       = 0x0001
+	mapiOverrideMeetingType   = 0x0002
+	mapiOverrideReminderDelta = 0x0004
+	mapiOverrideReminder      = 0x0008
+	mapiOverrideLocation      = 0x0010
+	mapiOverrideBusyStatus    = 0x0020
+	mapiOverrideAttachment    = 0x0040
+	mapiOverrideSubType       = 0x0080
+	mapiOverrideColor         = 0x0100
+)
+
+// The number of periods searched for the last occurrence of a bounded rule.
+const mapiHardLimit = 100000
+
+var mapiEpoch = time.Date(1601, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// MAPIModifiedInstance is an occurrence that was moved or changed.
+// OriginalStart is the occurrence it replaces, its RECURRENCE-ID.
+type MAPIModifiedInstance struct {
+	OriginalStart time.Time
+	Start         time.Time
+	End           time.Time
+}
+
+// MAPIRecurrence is an AppointmentRecurrencePattern. Deleted instances are
+// the ExceptionsToRule of Rule, modified instances are still generated by
+// the rule and are listed with their new times.
+type MAPIRecurrence struct {
+	Rule              *RecurringRule
+	Duration          time.Duration
+	ModifiedInstances []MAPIModifiedInstance
+}
+
+func mapiTime(minutes uint32, loc *time.Location) time.Time {
+	t := time.Unix(mapiEpoch.Unix()+int64(minutes)*60, 0).UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+}
+
+func mapiMinutes(t time.Time) uint32 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	return uint32((wall.Unix() - mapiEpoch.Unix()) / 60)
+}
+
+func mapiDate(minutes uint32) uint32 {
+	return minutes - minutes%1440
+}
+
+func mapiDaysFromMask(mask uint32) []ForDay {
+	var days []ForDay
+	for d := time.Sunday; d <= time.Saturday; d += 1 {
+		if mask&(1<<uint(d)) != 0 {
+			days = append(days, ForDay{Weekday: d})
+		}
+	}
+	return days
+}
+
+func mapiMaskFromDays(days []ForDay) uint32 {
+	var mask uint32
+	for _, fd := range days {
+		mask |= 1 << uint(fd.Weekday)
+	}
+	return mask
+}
+
+type mapiReader struct {
+	data []byte
+	err  error
+}
+
+func (r *mapiReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = errors.New("MAPI recurrence is truncated")
+		return nil
+	}
+	value := r.data[:n]
+	r.data = r.data[n:]
+	return value
+}
+
+func (r *mapiReader) u16() uint16 {
+	if value := r.next(2); value != nil {
+		return binary.LittleEndian.Uint16(value)
+	}
+	return 0
+}
+
+func (r *mapiReader) u32() uint32 {
+	if value := r.next(4); value != nil {
+		return binary.LittleEndian.Uint32(value)
+	}
+	return 0
+}
+
+func (r *mapiReader) u32s(count uint32) []uint32 {
+	var values []uint32
+	if uint64(count)*4 > uint64(len(r.data)) {
+		r.next(-1)
+		return nil
+	}
+	for i := uint32(0); i < count; i += 1 {
+		values = append(values, r.u32())
+	}
+	return values
+}
+
+func (r *mapiReader) skip(n uint32) {
+	if uint64(n) > uint64(len(r.data)) {
+		r.next(-1)
+		return
+	}
+	r.next(int(n))
+}
+
+type mapiException struct {
+	start, end, original uint32
+	flags                uint16
+}
+
+// DecodeMAPIRecurrence reads a PidLidAppointmentRecur blob, the local times
+// in it are interpreted in loc. Only the times of modified instances are
+// decoded, other overrides such as the subject are skipped.
+func DecodeMAPIRecurrence(data []byte, loc *time.Location) (*MAPIRecurrence, error) {
+	ce := &ConversionError{Format: "MAPI"}
+	r := &mapiReader{data: data}
+
+	if version := r.u16(); r.err == nil && version != 0x3004 {
+		return nil, errors.New(fmt.Sprintf("unsupported ReaderVersion 0x%04X", version))
+	}
+	r.u16() // WriterVersion
+
+	frequency := r.u16()
+	patternType := r.u16()
+	calendarType := r.u16()
+	r.u32() // FirstDateTime, implied by StartDate
+	period := r.u32()
+	r.u32() // SlidingFlag, only used by tasks
+
+	var mask, day, nth uint32
+
+	switch patternType {
+	case mapiPatternDay:
+	case mapiPatternWeek:
+		mask = r.u32()
+	case mapiPatternMonth, mapiPatternMonthEnd:
+		day = r.u32()
+	case mapiPatternMonthNth:
+		mask = r.u32()
+		nth = r.u32()
+	default:
+		// The Hijri patterns only make sense with a lunar calendar.
+		ce.Add("pattern type 0x%04X is not supported", patternType)
+		return nil, ce
+	}
+
+	endType := r.u32()
+	count := r.u32()
+	firstDOW := r.u32()
+	deleted := r.u32s(r.u32())
+	r.u32s(r.u32()) // ModifiedInstanceDates, repeated in the exceptions
+	startDate := r.u32()
+	endDate := r.u32()
+
+	if version := r.u32(); r.err == nil && version < 0x3006 {
+		return nil, errors.New(fmt.Sprintf("unsupported ReaderVersion2 0x%08X", version))
+	}
+	writerVersion2 := r.u32()
+	startOffset := r.u32()
+	endOffset := r.u32()
+
+	exceptions := make([]mapiException, r.u16())
+	for i := range exceptions {
+		ex := &exceptions[i]
+		ex.start, ex.end, ex.original = r.u32(), r.u32(), r.u32()
+		ex.flags = r.u16()
+
+		if ex.flags&mapiOverrideSubject != 0 {
+			r.u16()
+			r.skip(uint32(r.u16()))
+		}
+		for _, flag := range []uint16{mapiOverrideMeetingType, mapiOverrideReminderDelta, mapiOverrideReminder} {
+			if ex.flags&flag != 0 {
+				r.u32()
+			}
+		}
+		if ex.flags&mapiOverrideLocation != 0 {
+			r.u16()
+			r.skip(uint32(r.u16()))
+		}
+		for _, flag := range []uint16{mapiOverrideBusyStatus, mapiOverrideAttachment, mapiOverrideSubType, mapiOverrideColor} {
+			if ex.flags&flag != 0 {
+				r.u32()
+			}
+		}
+	}
+
+	r.skip(r.u32()) // ReservedBlock1
+
+	for _, ex := range exceptions {
+		if writerVersion2 >= 0x3009 {
+			r.skip(r.u32()) // ChangeHighlight
+		}
+		r.skip(r.u32()) // ReservedBlockEE1
+
+		if ex.flags&(mapiOverrideSubject|mapiOverrideLocation) != 0 {
+			r.u32s(3)
+			if ex.flags&mapiOverrideSubject != 0 {
+				r.skip(2 * uint32(r.u16()))
+			}
+			if ex.flags&mapiOverrideLocation != 0 {
+				r.skip(2 * uint32(r.u16()))
+			}
+			r.skip(r.u32()) // ReservedBlockEE2
+		}
+	}
+
+	r.skip(r.u32()) // ReservedBlock2
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	switch calendarType {
+	case 0, 1, 2, 9, 10, 11, 12:
+		// Every gregorian calendar, they only differ in their names.
+	default:
+		ce.Add("calendar type %d is not supported", calendarType)
+	}
+
+	rr := &RecurringRule{
+		DtStart:       mapiTime(startDate+startOffset, loc),
+		Interval:      1,
+		WorkWeekStart: time.Weekday(firstDOW),
+	}
+
+	if firstDOW > 6 {
+		ce.Add("invalid FirstDOW %d", firstDOW)
+	}
+
+	switch frequency {
+	case mapiFrequencyDaily:
+		rr.Frequency = DAILY
+		if patternType == mapiPatternDay {
+			if period == 0 || period%1440 != 0 {
+				ce.Add("daily period %d is not a whole number of days", period)
+			}
+			rr.Interval = int(period / 1440)
+		}
+	case mapiFrequencyWeekly:
+		rr.Frequency = WEEKLY
+		rr.Interval = int(period)
+	case mapiFrequencyMonthly:
+		rr.Frequency = MONTHLY
+		rr.Interval = int(period)
+	case mapiFrequencyYearly:
+		rr.Frequency = YEARLY
+		rr.Interval = int(period / 12)
+		rr.ByMonth = []int16{int16(rr.DtStart.Month())}
+		if period == 0 || period%12 != 0 {
+			ce.Add("yearly period %d is not a whole number of years", period)
+		}
+	default:
+		ce.Add("unknown RecurFrequency 0x%04X", frequency)
+	}
+
+	var month int
+	if rr.Frequency == YEARLY {
+		month = int(rr.DtStart.Month())
+	}
+
+	switch patternType {
+	case mapiPatternWeek:
+		rr.ByDay = mapiDaysFromMask(mask)
+		if len(rr.ByDay) == 0 {
+			ce.Add("weekly pattern without days")
+		}
+	case mapiPatternMonth:
+		clampedMonthDayInto(rr, int(day), month, ce)
+	case mapiPatternMonthEnd:
+		rr.ByMonthDay = []int16{-1}
+	case mapiPatternMonthNth:
+		days := mapiDaysFromMask(mask)
+		if len(days) == 0 || nth < 1 || nth > 5 {
+			ce.Add("invalid nth pattern 0x%02X %d", mask, nth)
+			break
+		}
+		offset := int(nth)
+		if nth == 5 {
+			offset = -1
+		}
+		relativeDaysInto(rr, days, offset)
+	}
+
+	switch endType {
+	case mapiEndAfterDate:
+		rr.Until = mapiTime(mapiDate(endDate)+startOffset, loc)
+	case mapiEndAfterCount:
+		rr.Count = int(count)
+	case mapiNeverEnd, 0xFFFFFFFF:
+	default:
+		ce.Add("unknown EndType 0x%08X", endType)
+	}
+
+	mr := &MAPIRecurrence{
+		Rule:     rr,
+		Duration: time.Duration(int64(endOffset)-int64(startOffset)) * time.Minute,
+	}
+
+	// Modified instances are in the deleted list as well, only the rest
+	// were actually deleted.
+	var modified = map[uint32]bool{}
+	for _, ex := range exceptions {
+		modified[mapiDate(ex.original)] = true
+		mr.ModifiedInstances = append(mr.ModifiedInstances, MAPIModifiedInstance{
+			OriginalStart: mapiTime(ex.original, loc),
+			Start:         mapiTime(ex.start, loc),
+			End:           mapiTime(ex.end, loc),
+		})
+	}
+
+	for _, date := range deleted {
+		if !modified[mapiDate(date)] {
+			rr.ExceptionsToRule = append(rr.ExceptionsToRule, mapiTime(mapiDate(date)+startOffset, loc))
+		}
+	}
+
+	if err := ce.Err(); err != nil {
+		return nil, err
+	}
+
+	if endType == mapiEndAfterCount {
+		// OccurrenceCount includes the deleted instances, COUNT is counted
+		// after the EXDATEs are removed.
+		var exDates = map[int64]bool{}
+		for _, exDate := range rr.ExceptionsToRule {
+			exDates[exDate.Unix()] = true
+		}
+
+		series := rr.Clone()
+		series.ExceptionsToRule = nil
+
+		var event time.Time
+		iter := series.Iterator().HardLimit(mapiHardLimit)
+		for iter.Step(&event) {
+			if exDates[event.Unix()] {
+				rr.Count -= 1
+			}
+		}
+
+		if rr.Count < 1 {
+			ce.Add("all %d instances are deleted", count)
+			return nil, ce
+		}
+	}
+
+	return mr, rr.internal_parser()
+}
+
+// mapiFirstDateTime calculates FirstDateTime, the start of the first
+// period counted from 1601 ([MS-OXOCAL] section 2.2.1.44.1.2).
+func mapiFirstDateTime(frequency, patternType uint16, period uint32, start time.Time, firstDOW time.Weekday) uint32 {
+	startDate := mapiDate(mapiMinutes(start))
+
+	switch {
+	case patternType == mapiPatternDay:
+		return startDate % period
+	case patternType == mapiPatternWeek:
+		back := (int(start.Weekday()) - int(firstDOW) + 7) % 7
+		return (startDate - uint32(back)*1440) % (period * 7 * 1440)
+	}
+
+	months := ((start.Year()-1601)*12 + int(start.Month()) - 1) % int(period)
+	return mapiMinutes(time.Date(1601, time.Month(1+months), 1, 0, 0, 0, 0, time.UTC))
+}
+
+// Encode writes an AppointmentRecurrencePattern, the reverse of
+// DecodeMAPIRecurrence. Modified instances are written with their times
+// only, without any other overrides.
+func (mr *MAPIRecurrence) Encode() ([]byte, error) {
+	ce := &ConversionError{Format: "MAPI"}
+	rr := mr.Rule
+
+	if rr.DtStart.Equal(EmptyTime) {
+		ce.Add("DtStart is required")
+	}
+	if rr.DtStart.Second() != 0 {
+		ce.Add("DtStart must be on a whole minute")
+	}
+	if len(rr.BySecond) > 0 || len(rr.ByMinute) > 0 || len(rr.ByHour) > 0 {
+		ce.Add("BYHOUR, BYMINUTE and BYSECOND can not be represented")
+	}
+	if len(rr.ByYearDay) > 0 || len(rr.ByWeekNo) > 0 {
+		ce.Add("BYYEARDAY and BYWEEKNO can not be represented")
+	}
+
+	interval := uint32(rr.Interval)
+	if interval < 1 {
+		interval = 1
+	}
+
+	var frequency, patternType uint16
+	var period uint32
+	var specific []uint32
+
+	weekly := func() {
+		patternType = mapiPatternWeek
+		for _, fd := range rr.ByDay {
+			if fd.Offset != 0 {
+				ce.Add("BYDAY=%s can not be represented", fd)
+			}
+		}
+		days := rr.ByDay
+		if len(days) == 0 {
+			days = []ForDay{{Weekday: rr.DtStart.Weekday()}}
+		}
+		specific = []uint32{mapiMaskFromDays(days)}
+	}
+
+	switch rr.Frequency {
+	case DAILY, WEEKLY:
+		if len(rr.ByMonthDay) > 0 || len(rr.ByMonth) > 0 || len(rr.BySetPos) > 0 {
+			ce.Add("FREQ=%s can only be limited by BYDAY", rr.Frequency)
+		}
+
+		switch {
+		case rr.Frequency == WEEKLY:
+			frequency, period = mapiFrequencyWeekly, interval
+			weekly()
+		case len(rr.ByDay) == 0:
+			frequency, patternType, period = mapiFrequencyDaily, mapiPatternDay, interval*1440
+		case interval == 1:
+			// Every weekday is a daily recurrence with a weekly pattern.
+			frequency, period = mapiFrequencyDaily, 1
+			weekly()
+		default:
+			ce.Add("DAILY with an INTERVAL and BYDAY can not be represented")
+		}
+	case MONTHLY, YEARLY:
+		var month int
+
+		frequency, period = mapiFrequencyMonthly, interval
+		if rr.Frequency == YEARLY {
+			frequency, period = mapiFrequencyYearly, interval*12
+			month = int(rr.DtStart.Month())
+
+			if len(rr.ByMonth) > 1 || (len(rr.ByMonth) == 1 && int(rr.ByMonth[0]) != month) {
+				ce.Add("BYMONTH must be the month of DtStart")
+			}
+			if len(rr.ByMonth) == 0 && len(rr.ByDay) > 0 {
+				ce.Add("BYDAY in a YEARLY rule requires BYMONTH")
+			}
+		} else if len(rr.ByMonth) > 0 {
+			ce.Add("BYMONTH in a MONTHLY rule can not be represented")
+		}
+
+		if len(rr.ByDay) > 0 {
+			if len(rr.ByMonthDay) > 0 {
+				ce.Add("BYDAY with BYMONTHDAY can not be represented")
+			}
+
+			days, offset := rr.relativeDays(ce)
+			if offset == -1 {
+				offset = 5
+			} else if offset < 1 || offset > 4 {
+				ce.Add("only the first four or the last day can be represented, got %d", offset)
+			}
+
+			patternType = mapiPatternMonthNth
+			specific = []uint32{mapiMaskFromDays(days), uint32(offset)}
+		} else {
+			patternType = mapiPatternMonth
+			specific = []uint32{uint32(rr.clampedMonthDay(month, ce))}
+		}
+	default:
+		ce.Add("FREQ=%s can not be represented", rr.Frequency)
+	}
+
+	endType := uint32(mapiNeverEnd)
+	count := uint32(10)
+	endDate := uint32(mapiNoEndDate)
+
+	if rr.Count > 0 || !rr.Until.Equal(EmptyTime) {
+		endType = mapiEndAfterDate
+		if rr.Count > 0 {
+			endType = mapiEndAfterCount
+		}
+
+		// Outlook stores both the count and the date of the last
+		// instance, deleted instances included.
+		series := rr.Clone()
+		series.ExceptionsToRule = nil
+
+		if rr.Count > 0 {
+			// COUNT leaves the deleted instances out, so the series runs
+			// until the last instance that isn't deleted.
+			var event, last time.Time
+			iter := rr.Iterator().HardLimit(mapiHardLimit)
+			for iter.Step(&event) {
+				last = event
+			}
+			series.Count = 0
+			series.Until = last
+		}
+
+		var event, last time.Time
+		count = 0
+
+		iter := series.Iterator().HardLimit(mapiHardLimit)
+		for iter.Step(&event) {
+			count += 1
+			last = event
+		}
+
+		if count == 0 || iter.IsHardLimitReached() {
+			ce.Add("the last occurrence could not be found")
+		}
+		endDate = mapiDate(mapiMinutes(last))
+	}
+
+	if err := ce.Err(); err != nil {
+		return nil, err
+	}
+
+	var deleted, modifiedDates []uint32
+	for _, exDate := range rr.ExceptionsToRule {
+		deleted = append(deleted, mapiDate(mapiMinutes(exDate.In(rr.DtStart.Location()))))
+	}
+
+	instances := append([]MAPIModifiedInstance(nil), mr.ModifiedInstances...)
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Start.Before(instances[j].Start)
+	})
+
+	for _, mi := range instances {
+		deleted = append(deleted, mapiDate(mapiMinutes(mi.OriginalStart)))
+		modifiedDates = append(modifiedDates, mapiDate(mapiMinutes(mi.Start)))
+	}
+
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i] < deleted[j] })
+
+	startOffset := uint32(rr.DtStart.Hour()*60 + rr.DtStart.Minute())
+
+	var buf bytes.Buffer
+	write := func(values ...interface{}) {
+		for _, value := range values {
+			binary.Write(&buf, binary.LittleEndian, value)
+		}
+	}
+
+	write(uint16(0x3004), uint16(0x3004), frequency, patternType, uint16(0))
+	write(mapiFirstDateTime(frequency, patternType, period, rr.DtStart, rr.WorkWeekStart))
+	write(period, uint32(0))
+	write(specific)
+	write(endType, count, uint32(rr.WorkWeekStart))
+	write(uint32(len(deleted)), deleted)
+	write(uint32(len(modifiedDates)), modifiedDates)
+	write(mapiDate(mapiMinutes(rr.DtStart)), endDate)
+
+	write(uint32(0x3006), uint32(0x3008))
+	write(startOffset, startOffset+uint32(mr.Duration/time.Minute))
+
+	write(uint16(len(instances)))
+	for _, mi := range instances {
+		write(mapiMinutes(mi.Start), mapiMinutes(mi.End), mapiMinutes(mi.OriginalStart), uint16(0))
+	}
+
+	write(uint32(0)) // ReservedBlock1
+	for range instances {
+		write(uint32(0)) // ReservedBlockEE1
+	}
+	write(uint32(0)) // ReservedBlock2
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,185 @@
+package rrule
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+// The fixtures in testdata/mapi are written by testdata/mapi/gen.go from
+// the layout in [MS-OXOCAL] 2.2.1.44, not by the encoder, but they aren't
+// blobs from Outlook. FirstDateTime is worked out the same way as
+// mapiFirstDateTime, so it isn't checked independently.
+//
+//go:generate go run testdata/mapi/gen.go
+var mapiFixtures = map[string]string{
+	"weekly_exceptions.bin": "DTSTART;TZID=UTC:20240108T090000Z\n" +
+		"EXDATE;TZID=UTC:20240122T090000Z\n" +
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;WKST=MO;BYDAY=MO,WE;COUNT=9",
+	"monthly_last_weekday.bin": "DTSTART;TZID=UTC:20240131T140000Z\n" +
+		"RRULE:FREQ=MONTHLY;WKST=SU;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;UNTIL=20240628T140000Z",
+	"daily_no_end.bin": "DTSTART;TZID=UTC:20240301T081500Z\n" +
+		"EXDATE;TZID=UTC:20240307T081500Z\n" +
+		"RRULE:FREQ=DAILY;INTERVAL=3;WKST=SU",
+	"yearly_fourth_thursday.bin": "DTSTART;TZID=UTC:20241128T120000Z\n" +
+		"RRULE:FREQ=YEARLY;WKST=SU;BYMONTH=11;BYDAY=4TH;COUNT=5",
+	"every_weekday.bin": "DTSTART;TZID=UTC:20240101T070000Z\n" +
+		"RRULE:FREQ=DAILY;WKST=SU;BYDAY=MO,TU,WE,TH,FR;COUNT=10",
+}
+
+func readMAPIFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/mapi/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func Test_MAPI_Decode(t *testing.T) {
+	for name, expected := range mapiFixtures {
+		mr, err := DecodeMAPIRecurrence(readMAPIFixture(t, name), time.UTC)
+		if err != nil {
+			t.Log("Failed to decode", name, err)
+			t.Fail()
+			continue
+		}
+
+		expectedRule, _ := Parse(expected)
+		if !mr.Rule.Equal(expectedRule) {
+			t.Log("Rules don't match", name, mr.Rule)
+			t.Fail()
+		}
+	}
+}
+
+func Test_MAPI_ModifiedInstances(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	mr, err := DecodeMAPIRecurrence(readMAPIFixture(t, "weekly_exceptions.bin"), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mr.Duration != 30*time.Minute {
+		t.Log("Unexpected duration", mr.Duration)
+		t.Fail()
+	}
+
+	if !mr.Rule.DtStart.Equal(time.Date(2024, time.January, 8, 9, 0, 0, 0, loc)) {
+		t.Log("Times should be local to the location", mr.Rule.DtStart)
+		t.Fail()
+	}
+
+	expected := MAPIModifiedInstance{
+		OriginalStart: time.Date(2024, time.February, 5, 9, 0, 0, 0, loc),
+		Start:         time.Date(2024, time.February, 6, 10, 0, 0, 0, loc),
+		End:           time.Date(2024, time.February, 6, 10, 45, 0, 0, loc),
+	}
+
+	if len(mr.ModifiedInstances) != 1 || mr.ModifiedInstances[0] != expected {
+		t.Log("Unexpected modified instances", mr.ModifiedInstances)
+		t.Fail()
+	}
+
+	// The moved instance is in the deleted list of the blob, but it's not
+	// an exception to the rule.
+	if len(mr.Rule.ExceptionsToRule) != 1 {
+		t.Log("Unexpected exceptions", mr.Rule.ExceptionsToRule)
+		t.Fail()
+	}
+}
+
+func Test_MAPI_LastOccurrence(t *testing.T) {
+	// The EndDate of each blob, the deleted instance of
+	// weekly_exceptions.bin doesn't move its end.
+	var cases = map[string]time.Time{
+		"weekly_exceptions.bin":      time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC),
+		"monthly_last_weekday.bin":   time.Date(2024, time.June, 28, 14, 0, 0, 0, time.UTC),
+		"yearly_fourth_thursday.bin": time.Date(2028, time.November, 23, 12, 0, 0, 0, time.UTC),
+		"every_weekday.bin":          time.Date(2024, time.January, 12, 7, 0, 0, 0, time.UTC),
+	}
+
+	for name, expected := range cases {
+		mr, err := DecodeMAPIRecurrence(readMAPIFixture(t, name), time.UTC)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+
+		var event, last time.Time
+		iter := mr.Rule.Iterator().HardLimit(1000)
+		for iter.Step(&event) {
+			last = event
+		}
+		if !last.Equal(expected) {
+			t.Log("Unexpected last occurrence", name, last, expected)
+			t.Fail()
+		}
+	}
+}
+
+func Test_MAPI_Encode(t *testing.T) {
+	for name := range mapiFixtures {
+		data := readMAPIFixture(t, name)
+
+		mr, err := DecodeMAPIRecurrence(data, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := mr.Encode()
+		if err != nil {
+			t.Log("Failed to encode", name, err)
+			t.Fail()
+			continue
+		}
+
+		// Overrides other than the times aren't written, so only compare
+		// blobs without them.
+		if name != "weekly_exceptions.bin" && !bytes.Equal(encoded, data) {
+			t.Logf("Blobs don't match for %s\n%x\n%x", name, encoded, data)
+			t.Fail()
+		}
+
+		decoded, err := DecodeMAPIRecurrence(encoded, time.UTC)
+		if err != nil || !decoded.Rule.Equal(mr.Rule) || len(decoded.ModifiedInstances) != len(mr.ModifiedInstances) {
+			t.Log("Round trip failed", name, err)
+			t.Fail()
+		}
+	}
+
+	var unsupported = []string{
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=HOURLY",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=DAILY;BYHOUR=9,17",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=MONTHLY;BYDAY=-2MO",
+		"DTSTART;TZID=UTC:20240101T090000Z\nRRULE:FREQ=YEARLY;BYMONTH=3",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse(value)
+
+		if _, err := (&MAPIRecurrence{Rule: rule}).Encode(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
+
+func Test_MAPI_Invalid(t *testing.T) {
+	data := readMAPIFixture(t, "daily_no_end.bin")
+
+	for _, length := range []int{0, 2, 30, len(data) - 1} {
+		if _, err := DecodeMAPIRecurrence(data[:length], time.UTC); err == nil {
+			t.Log("Expected error for truncated blob", length)
+			t.Fail()
+		}
+	}
+
+	hijri := append([]byte(nil), data...)
+	hijri[6] = 0x0A
+
+	if _, err := DecodeMAPIRecurrence(hijri, time.UTC); err == nil {
+		t.Log("Expected error for a Hijri pattern")
+		t.Fail()
+	}
+}
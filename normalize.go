@@ -0,0 +1,220 @@
+package rrule
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// How many occurrences SemanticallyEqual compares of rules without an end
+// when the normalized rules differ, and how many periods it steps through
+// before giving up.
+const (
+	semanticLimit     = 1000
+	semanticHardLimit = 100000
+)
+
+// Normalize returns an equivalent copy of the rule in a canonical form, so
+// rules with the same occurrences are more likely to be Equal:
+//
+//   - lists are sorted and duplicates removed, BYDAY from Monday to Sunday
+//   - BYHOUR, BYMINUTE and BYSECOND are dropped when they only repeat the
+//     time of DtStart, BYMONTH and BYDAY when they list every month or day
+//     of a rule that only filters by them
+//   - WKST is set to Monday when it makes no difference
+//   - the days implied by DtStart are filled in, FREQ=WEEKLY starting on a
+//     Monday gets BYDAY=MO
+//   - UNTIL is in UTC and EXDATEs are in the location of DtStart
+func (rr *RecurringRule) Normalize() *RecurringRule {
+	result := rr.Clone()
+	start := result.DtStart
+
+	if result.Interval < 1 {
+		result.Interval = 1
+	}
+
+	result.BySecond = sortedUnique(result.BySecond)
+	result.ByMinute = sortedUnique(result.ByMinute)
+	result.ByHour = sortedUnique(result.ByHour)
+	result.ByMonthDay = sortedUnique(result.ByMonthDay)
+	result.ByYearDay = sortedUnique(result.ByYearDay)
+	result.ByWeekNo = sortedUnique(result.ByWeekNo)
+	result.ByMonth = sortedUnique(result.ByMonth)
+	result.BySetPos = sortedUnique(result.BySetPos)
+	result.ByDay = sortedUniqueDays(result.ByDay)
+
+	if result.Frequency <= MINUTELY && isOnly(result.BySecond, start.Second()) {
+		result.BySecond = nil
+	}
+	if result.Frequency <= HOURLY && isOnly(result.ByMinute, start.Minute()) {
+		result.ByMinute = nil
+	}
+	if result.Frequency <= DAILY && isOnly(result.ByHour, start.Hour()) {
+		result.ByHour = nil
+	}
+
+	if result.Frequency != YEARLY && len(result.ByMonth) == 12 {
+		result.ByMonth = nil
+	}
+	if result.Frequency >= DAILY && isEveryWeekday(result.ByDay) {
+		result.ByDay = nil
+	}
+
+	result.fillImpliedDays()
+
+	// WKST only matters for numbered weeks, weeks that are skipped and
+	// positions in a week.
+	weekly := result.Frequency == WEEKLY && (result.Interval > 1 && len(result.ByDay) > 0 || len(result.BySetPos) > 0)
+	if !weekly && len(result.ByWeekNo) == 0 {
+		result.WorkWeekStart = time.Monday
+	}
+
+	if !result.Until.Equal(EmptyTime) {
+		result.Until = result.Until.UTC()
+	}
+
+	var exceptions []time.Time
+	for _, ex := range result.ExceptionsToRule {
+		exceptions = append(exceptions, ex.In(start.Location()))
+	}
+	sort.Slice(exceptions, func(i, j int) bool { return exceptions[i].Before(exceptions[j]) })
+
+	result.ExceptionsToRule = nil
+	for i, ex := range exceptions {
+		if i == 0 || !ex.Equal(exceptions[i-1]) {
+			result.ExceptionsToRule = append(result.ExceptionsToRule, ex)
+		}
+	}
+
+	return result
+}
+
+// fillImpliedDays writes out the days RFC 5545 takes from DtStart when a
+// rule doesn't say.
+func (rr *RecurringRule) fillImpliedDays() {
+	start := rr.DtStart
+	day := []int16{int16(start.Day())}
+	weekday := []ForDay{{Weekday: start.Weekday()}}
+
+	noDays := len(rr.ByDay) == 0 && len(rr.ByMonthDay) == 0 && len(rr.ByYearDay) == 0
+
+	switch rr.Frequency {
+	case YEARLY:
+		switch {
+		case noDays && len(rr.ByWeekNo) > 0:
+			rr.ByDay = weekday
+		case noDays && len(rr.ByMonth) > 0:
+			rr.ByMonthDay = day
+		case noDays:
+			rr.ByMonth = []int16{int16(start.Month())}
+			rr.ByMonthDay = day
+		}
+	case MONTHLY:
+		if noDays {
+			rr.ByMonthDay = day
+		}
+	case WEEKLY:
+		if noDays {
+			rr.ByDay = weekday
+		}
+	}
+}
+
+func isEveryWeekday(days []ForDay) bool {
+	seen := map[time.Weekday]bool{}
+	for _, fd := range days {
+		if fd.Offset != 0 {
+			return false
+		}
+		seen[fd.Weekday] = true
+	}
+	return len(seen) == 7
+}
+
+func isOnly(values []int16, value int) bool {
+	return len(values) == 1 && int(values[0]) == value
+}
+
+func sortedUnique(values []int16) []int16 {
+	var results []int16
+	sorted := append([]int16(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			results = append(results, v)
+		}
+	}
+	return results
+}
+
+func sortedUniqueDays(days []ForDay) []ForDay {
+	var results []ForDay
+	sorted := append([]ForDay(nil), days...)
+
+	// Monday first, whatever WKST is.
+	order := func(fd ForDay) int { return (int(fd.Weekday) + 6) % 7 }
+	sort.Slice(sorted, func(i, j int) bool {
+		if order(sorted[i]) != order(sorted[j]) {
+			return order(sorted[i]) < order(sorted[j])
+		}
+		return sorted[i].Offset < sorted[j].Offset
+	})
+
+	for i, fd := range sorted {
+		if i == 0 || fd != sorted[i-1] {
+			results = append(results, fd)
+		}
+	}
+	return results
+}
+
+// SemanticallyEqual returns true when both rules have the same
+// occurrences. Rules that are Equal once normalized are, otherwise the
+// occurrences are compared, all of them for rules with an UNTIL or COUNT
+// and up to the first thousand for rules without an end. When that takes
+// too long to tell, it's false.
+func (rr *RecurringRule) SemanticallyEqual(other *RecurringRule) bool {
+	if rr.Normalize().Equal(other.Normalize()) {
+		return true
+	}
+
+	var a, b time.Time
+	iterA := rr.semanticIterator()
+	iterB := other.semanticIterator()
+
+	for {
+		okA := iterA.Step(&a)
+		okB := iterB.Step(&b)
+
+		if iterA.IsHardLimitReached() || iterB.IsHardLimitReached() {
+			return false
+		}
+		if okA != okB {
+			return false
+		}
+		if !okA {
+			return true
+		}
+		if !a.Equal(b) {
+			return false
+		}
+	}
+}
+
+func (rr *RecurringRule) semanticIterator() *RecurrenceIterator {
+	iter := rr.Iterator().HardLimit(semanticHardLimit)
+	if rr.Count == 0 && rr.Until.Equal(EmptyTime) {
+		iter.Limit(semanticLimit)
+	}
+	return iter
+}
+
+// Fingerprint returns a hash of the normalized rule, rules that normalize
+// to the same form have the same fingerprint. DtStart and its location are
+// part of the rule, so they're part of the fingerprint.
+func (rr *RecurringRule) Fingerprint() string {
+	sum := sha256.Sum256([]byte(rr.Normalize().String()))
+	return hex.EncodeToString(sum[:])
+}
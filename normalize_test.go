@@ -0,0 +1,167 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Normalize(t *testing.T) {
+	var cases = []struct {
+		rule     string
+		expected string
+	}{
+		{
+			"RRULE:FREQ=WEEKLY;BYDAY=WE,MO,WE",
+			"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		},
+		{
+			"RRULE:FREQ=WEEKLY",
+			"RRULE:FREQ=WEEKLY;BYDAY=TU",
+		},
+		{
+			"RRULE:FREQ=MONTHLY;BYHOUR=9;BYMINUTE=0;WKST=SU",
+			"RRULE:FREQ=MONTHLY;BYMONTHDAY=2",
+		},
+		{
+			"RRULE:FREQ=YEARLY",
+			"RRULE:FREQ=YEARLY;BYMONTH=9;BYMONTHDAY=2",
+		},
+		{
+			"RRULE:FREQ=YEARLY;BYMONTH=3,1",
+			"RRULE:FREQ=YEARLY;BYMONTH=1,3;BYMONTHDAY=2",
+		},
+		{
+			"RRULE:FREQ=DAILY;BYDAY=SU,MO,TU,WE,TH,FR,SA;BYMONTH=1,2,3,4,5,6,7,8,9,10,11,12",
+			"RRULE:FREQ=DAILY",
+		},
+		{
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU;WKST=SU",
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU;WKST=SU",
+		},
+		{
+			// Every other week from the week with DTSTART, which WKST
+			// decides the Sunday of.
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU;WKST=SU",
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU;WKST=SU",
+		},
+		{
+			// The first day of each week, counted from WKST.
+			"RRULE:FREQ=WEEKLY;BYDAY=MO,TU;BYSETPOS=1;WKST=TU",
+			"RRULE:FREQ=WEEKLY;BYDAY=MO,TU;BYSETPOS=1;WKST=TU",
+		},
+		{
+			"RRULE:FREQ=MONTHLY;BYDAY=-1FR,1MO,FR",
+			"RRULE:FREQ=MONTHLY;BYDAY=1MO,-1FR,FR",
+		},
+	}
+
+	for _, c := range cases {
+		rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + c.rule)
+		expected, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + c.expected)
+
+		normalized := rule.Normalize()
+		if !normalized.Equal(expected) {
+			t.Log("Unexpected normal form", c.rule)
+			t.Log(normalized)
+			t.Fail()
+		}
+
+		// The normal form has the same occurrences.
+		sameOccurrences(t, rule.Iterator().Limit(50), normalized.Iterator().Limit(50), c.rule)
+	}
+}
+
+func Test_Normalize_Dates(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"RRULE:FREQ=DAILY;UNTIL=19971224T000000",
+	)
+	exception := time.Date(1997, time.September, 4, 13, 0, 0, 0, time.UTC)
+	rule = rule.WithExceptions(exception.Add(24*time.Hour), exception, exception)
+
+	normalized := rule.Normalize()
+
+	if normalized.Until.Location() != time.UTC || !normalized.Until.Equal(rule.Until) {
+		t.Log("UNTIL should be in UTC", normalized.Until)
+		t.Fail()
+	}
+
+	if len(normalized.ExceptionsToRule) != 2 || !normalized.ExceptionsToRule[0].Equal(exception) ||
+		normalized.ExceptionsToRule[0].Location() != rule.DtStart.Location() {
+		t.Log("Unexpected exceptions", normalized.ExceptionsToRule)
+		t.Fail()
+	}
+
+	if len(rule.ExceptionsToRule) != 3 {
+		t.Log("Normalize changed the original")
+		t.Fail()
+	}
+}
+
+func Test_SemanticallyEqual(t *testing.T) {
+	var cases = []struct {
+		a, b     string
+		expected bool
+	}{
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE", "RRULE:FREQ=WEEKLY;BYDAY=WE,MO", true},
+		{"RRULE:FREQ=WEEKLY", "RRULE:FREQ=WEEKLY;BYDAY=TU", true},
+		{"RRULE:FREQ=DAILY;COUNT=3", "RRULE:FREQ=DAILY;UNTIL=19970904T090000", true},
+		{"RRULE:FREQ=DAILY;INTERVAL=7", "RRULE:FREQ=WEEKLY", true},
+		{"RRULE:FREQ=DAILY;COUNT=3", "RRULE:FREQ=DAILY;COUNT=4", false},
+		{"RRULE:FREQ=DAILY;COUNT=2000", "RRULE:FREQ=DAILY;COUNT=1500", false},
+		{"RRULE:FREQ=DAILY;COUNT=2000", "RRULE:FREQ=DAILY", false},
+		// There's no February 30th or 31st, so neither ever ends.
+		{"RRULE:FREQ=DAILY;COUNT=5;BYMONTH=2;BYMONTHDAY=30", "RRULE:FREQ=DAILY;COUNT=5;BYMONTH=2;BYMONTHDAY=31", false},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO", "RRULE:FREQ=WEEKLY;BYDAY=TU", false},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU;WKST=SU", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", false},
+		{"RRULE:FREQ=WEEKLY;BYDAY=MO,TU;BYSETPOS=1;WKST=TU", "RRULE:FREQ=WEEKLY;BYDAY=MO,TU;BYSETPOS=1", false},
+	}
+
+	for _, c := range cases {
+		a, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + c.a)
+		b, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + c.b)
+
+		if a.SemanticallyEqual(b) != c.expected || b.SemanticallyEqual(a) != c.expected {
+			t.Log("Unexpected result", c.a, c.b, c.expected)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Fingerprint(t *testing.T) {
+	a, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=WE,MO;BYHOUR=9")
+	b, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE")
+	c, _ := Parse("DTSTART;TZID=UTC:19970902T100000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE")
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Log("Equivalent rules have different fingerprints")
+		t.Fail()
+	}
+
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Log("Different rules have the same fingerprint")
+		t.Fail()
+	}
+
+	// WKST picks the weeks of every other Sunday.
+	d, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU;WKST=SU")
+	e, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU;WKST=MO")
+	if d.Fingerprint() == e.Fingerprint() {
+		t.Log("WKST should be part of the fingerprint")
+		t.Fail()
+	}
+
+	// And the day BYSETPOS picks in each week.
+	f, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,TU;BYSETPOS=1;WKST=TU")
+	g, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,TU;BYSETPOS=1;WKST=MO")
+	if f.Fingerprint() == g.Fingerprint() {
+		t.Log("WKST should be part of the fingerprint with BYSETPOS")
+		t.Fail()
+	}
+
+	// Fingerprints are stored, they must not change between releases.
+	if fp := b.Fingerprint(); fp != "32d2c38f871db3d8d7775b4a33d7152749ed0e4849439e97fbd6c8c6be2a986e" {
+		t.Log("Fingerprint changed", fp)
+		t.Fail()
+	}
+}
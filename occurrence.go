@@ -0,0 +1,104 @@
+package rrule
+
+import (
+	"iter"
+	"time"
+)
+
+// OccurrenceSource is the property an occurrence comes from.
+type OccurrenceSource string
+
+const (
+	SourceRRule OccurrenceSource = "RRULE"
+	SourceRDate OccurrenceSource = "RDATE"
+)
+
+// Occurrence is an occurrence with its place in the series, as
+// StepOccurrence returns it.
+type Occurrence struct {
+	Time time.Time
+
+	// Index counts from 1 for the first occurrence of the series, EXDATEs
+	// aren't counted. It's 0 when it isn't known, stepping in Reverse or
+	// after Step skipped ahead to After.
+	Index int
+
+	// IsFirst and IsLast mark the ends of the series. Only rules with an
+	// UNTIL or COUNT have a last occurrence.
+	IsFirst bool
+	IsLast  bool
+
+	// RecurrenceID is the RECURRENCE-ID property of the instance, as
+	// ICalWriter writes it.
+	RecurrenceID string
+
+	// RecurringRule has no RDATEs, so all its occurrences are SourceRRule.
+	Source OccurrenceSource
+}
+
+// StepOccurrence is Step with the occurrence's place in the series. To
+// count them, After doesn't skip ahead when StepOccurrence is used from
+// the start, the periods before it are stepped through.
+func (ri *RecurrenceIterator) StepOccurrence(o *Occurrence) bool {
+	if !ri.started {
+		ri.counting = true
+	}
+
+	var t time.Time
+	if !ri.Step(&t) {
+		return false
+	}
+
+	first, last := ri.ends()
+	*o = Occurrence{
+		Time:         t,
+		IsFirst:      t.Equal(first),
+		IsLast:       !last.Equal(EmptyTime) && t.Equal(last),
+		RecurrenceID: icalDateTime("RECURRENCE-ID", t),
+		Source:       SourceRRule,
+	}
+	if !ri.reverse && !ri.seeked {
+		o.Index = ri.series - len(ri.iterBuffer)
+	}
+	return true
+}
+
+// ends returns the first occurrence of the rule and the last, which is
+// empty for rules without an end. They're found once, with iterators of
+// their own.
+func (ri *RecurrenceIterator) ends() (time.Time, time.Time) {
+	if ri.endsFound {
+		return ri.first, ri.final
+	}
+	ri.endsFound = true
+
+	forward := ri.rule.Iterator().HardLimit(ri.hardLimit)
+	if ri.ctx != nil {
+		forward.Context(ri.ctx)
+	}
+	forward.Step(&ri.first)
+
+	if ri.rule.Count > 0 || !ri.rule.Until.Equal(EmptyTime) {
+		backward := ri.rule.Iterator().Reverse().HardLimit(ri.hardLimit)
+		if ri.ctx != nil {
+			backward.Context(ri.ctx)
+		}
+		backward.Step(&ri.final)
+	}
+
+	return ri.first, ri.final
+}
+
+// Occurrences returns the occurrences of the rule with their place in the
+// series, for a range loop.
+func (rr *RecurringRule) Occurrences() iter.Seq[Occurrence] {
+	return func(yield func(Occurrence) bool) {
+		ri := rr.Iterator()
+		var o Occurrence
+		for ri.StepOccurrence(&o) {
+			if !yield(o) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Occurrence_Count(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"RRULE:FREQ=DAILY;COUNT=10\n" +
+			"EXDATE;TZID=America/New_York:19970904T090000",
+	)
+
+	var o Occurrence
+	var count int
+	iter := rule.Iterator()
+	for iter.StepOccurrence(&o) {
+		count += 1
+		if o.Index != count || o.IsFirst != (count == 1) || o.IsLast != (count == 10) || o.Source != SourceRRule {
+			t.Log("unexpected", count, o)
+			t.Fail()
+		}
+	}
+	if count != 10 {
+		t.Log("expected 10 occurrences, got", count)
+		t.Fail()
+	}
+
+	iter = rule.Iterator()
+	iter.StepOccurrence(&o)
+	if o.RecurrenceID != "RECURRENCE-ID;TZID=America/New_York:19970902T090000" {
+		t.Log("unexpected RECURRENCE-ID", o.RecurrenceID)
+		t.Fail()
+	}
+}
+
+func Test_Occurrence_After(t *testing.T) {
+	// The index is in the whole series, not the window.
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR")
+
+	var o Occurrence
+	iter := rule.Iterator().After(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))
+	if !iter.StepOccurrence(&o) {
+		t.Fatal("no occurrence after February 1st")
+	}
+
+	// Mondays and Fridays in January 2024, then Friday the 2nd.
+	if o.Index != 10 || o.IsFirst || o.IsLast || !o.Time.Equal(time.Date(2024, time.February, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Log("unexpected", o)
+		t.Fail()
+	}
+	if o.RecurrenceID != "RECURRENCE-ID:20240202T090000Z" {
+		t.Log("unexpected RECURRENCE-ID", o.RecurrenceID)
+		t.Fail()
+	}
+}
+
+func Test_Occurrence_Reverse(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=Europe/Berlin:20240101T090000\nRRULE:FREQ=MONTHLY;UNTIL=20241231T000000Z")
+
+	var occurrences []Occurrence
+	var o Occurrence
+	iter := rule.Iterator().Reverse()
+	for iter.StepOccurrence(&o) {
+		occurrences = append(occurrences, o)
+	}
+
+	if len(occurrences) != 12 {
+		t.Fatal("expected 12 occurrences, got", len(occurrences))
+	}
+	if !occurrences[0].IsLast || !occurrences[11].IsFirst || occurrences[0].Index != 0 {
+		t.Log("unexpected ends", occurrences[0], occurrences[11])
+		t.Fail()
+	}
+}
+
+func Test_Occurrence_Seq(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=HOURLY")
+
+	for o := range rule.Occurrences() {
+		if o.IsLast {
+			t.Log("a rule without an end has no last occurrence", o)
+			t.Fail()
+		}
+		if o.Index == 3 {
+			break
+		}
+	}
+}
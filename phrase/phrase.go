@@ -0,0 +1,1064 @@
+// Package phrase turns English phrases such as "every other Tuesday at 9am
+// until June" or "last Friday of every month" into recurring rules.
+//
+//	result, err := phrase.Parse("every other Tuesday at 9am", time.Now())
+//
+// The wording of rrule.English is understood as well, so a rule described
+// with Describe can be read back.
+package phrase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/graham/rrule"
+)
+
+// Result is a parsed phrase.
+type Result struct {
+	Rule *rrule.RecurringRule
+
+	// Confidence is between 0 and 1. It drops for every ignored word and
+	// for every assumption in Ambiguities.
+	Confidence float64
+
+	// Ambiguities describes the assumptions made while reading the phrase,
+	// such as which June "until June" refers to.
+	Ambiguities []string
+
+	// Ignored are the words that weren't understood.
+	Ignored []string
+}
+
+// The factor every ambiguity takes off the confidence.
+const ambiguityPenalty = 0.8
+
+// How many periods are searched for the first occurrence.
+const searchLimit = 1000
+
+var units = map[string]rrule.FrequencyValue{
+	"year": rrule.YEARLY, "years": rrule.YEARLY,
+	"month": rrule.MONTHLY, "months": rrule.MONTHLY,
+	"week": rrule.WEEKLY, "weeks": rrule.WEEKLY,
+	"day": rrule.DAILY, "days": rrule.DAILY,
+	"hour": rrule.HOURLY, "hours": rrule.HOURLY,
+	"minute": rrule.MINUTELY, "minutes": rrule.MINUTELY,
+	"second": rrule.SECONDLY, "seconds": rrule.SECONDLY,
+}
+
+var adverbs = map[string]struct {
+	freq     rrule.FrequencyValue
+	interval int
+}{
+	"yearly": {rrule.YEARLY, 1}, "annually": {rrule.YEARLY, 1},
+	"monthly": {rrule.MONTHLY, 1}, "weekly": {rrule.WEEKLY, 1},
+	"fortnightly": {rrule.WEEKLY, 2}, "biweekly": {rrule.WEEKLY, 2},
+	"daily": {rrule.DAILY, 1}, "hourly": {rrule.HOURLY, 1},
+}
+
+var weekdays = map[string]time.Weekday{}
+
+var workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+var weekend = []time.Weekday{time.Saturday, time.Sunday}
+
+var months = map[string]time.Month{}
+
+var numberWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5,
+	"sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10,
+}
+
+// Words that only join the others.
+var fillers = map[string]bool{
+	"the": true, "on": true, "in": true, "of": true, "and": true, "at": true,
+	"a": true, "an": true, "only": true, "for": true, "repeat": true,
+	"repeats": true, "repeating": true, "recurring": true,
+}
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		weekdays[name] = d
+		weekdays[name+"s"] = d
+		weekdays[name[:3]] = d
+	}
+	weekdays["tues"] = time.Tuesday
+	weekdays["thur"] = time.Thursday
+	weekdays["thurs"] = time.Thursday
+
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		months[name] = m
+		months[name[:3]] = m
+	}
+	months["sept"] = time.September
+}
+
+type clock struct {
+	hour, minute int
+}
+
+// position is one entry of a list such as "the first and last Friday",
+// weekday is only set when the entry names one.
+type position struct {
+	n       int
+	weekday time.Weekday
+	named   bool
+}
+
+type parser struct {
+	words []string
+	pos   int
+
+	reference time.Time
+	rule      *rrule.RecurringRule
+
+	hasFrequency bool
+	times        []clock
+	start        time.Time
+	hasStart     bool
+
+	ambiguities []string
+	ignored     []string
+
+	// err is the first clause that was understood but can't be used, such
+	// as a date that doesn't exist.
+	err error
+}
+
+// Parse reads an English phrase into a rule. DtStart is the first
+// occurrence on or after reference, at the time given in the phrase or at
+// the time of reference when there is none.
+func Parse(text string, reference time.Time) (*Result, error) {
+	p := &parser{
+		words:     tokenize(text),
+		reference: reference,
+		rule: &rrule.RecurringRule{
+			Interval:      1,
+			WorkWeekStart: time.Monday,
+		},
+	}
+
+	if len(p.words) == 0 {
+		return nil, errors.New("empty phrase")
+	}
+
+	for !p.done() {
+		if !p.parseNext() {
+			p.ignored = append(p.ignored, p.words[p.pos])
+			p.pos++
+		}
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err := p.finish(); err != nil {
+		return nil, err
+	}
+
+	confidence := float64(len(p.words)-len(p.ignored)) / float64(len(p.words))
+	for range p.ambiguities {
+		confidence *= ambiguityPenalty
+	}
+
+	return &Result{
+		Rule:        p.rule,
+		Confidence:  confidence,
+		Ambiguities: p.ambiguities,
+		Ignored:     p.ignored,
+	}, nil
+}
+
+func tokenize(text string) []string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("a.m.", "am", "p.m.", "pm", "o'clock", "").Replace(text)
+
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.' || r == ';' || r == '\t' || r == '\n'
+	})
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.words)
+}
+
+func (p *parser) peek(offset int) string {
+	if p.pos+offset < len(p.words) {
+		return p.words[p.pos+offset]
+	}
+	return ""
+}
+
+// accept consumes the next word if it's one of the given words.
+func (p *parser) accept(words ...string) bool {
+	for _, w := range words {
+		if p.peek(0) == w {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) ambiguous(format string, args ...interface{}) {
+	p.ambiguities = append(p.ambiguities, fmt.Sprintf(format, args...))
+}
+
+// fail records why the phrase can't be used, only the first reason is
+// kept.
+func (p *parser) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// parseNext reads the clause at the current word, it returns false when
+// the word isn't understood.
+func (p *parser) parseNext() bool {
+	word := p.peek(0)
+
+	switch {
+	case word == "every" || word == "each":
+		return p.parseEvery()
+	case word == "other":
+		// "every other" is read by parseEvery, "on other days" isn't.
+		return false
+	case word == "in" && isMonth(p.peek(1)):
+		p.pos++
+		p.setMonths(p.parseMonths())
+		return true
+	case isMonth(word):
+		p.setMonths(p.parseMonths())
+		return true
+	case word == "at":
+		return p.parseAt()
+	case word == "until" || word == "till" || word == "through" || word == "ending" || word == "ends":
+		return p.parseUntil()
+	case word == "starting" || word == "from" || word == "beginning" || word == "starts" || word == "begins":
+		return p.parseStart()
+	case word == "with":
+		return p.parseWeekStart()
+	case word == "except" || word == "excluding":
+		p.ambiguous("exceptions are not supported, %q was ignored", strings.Join(p.words[p.pos:], " "))
+		p.pos = len(p.words)
+		return true
+	case word == "once":
+		p.pos++
+		p.rule.Count = 1
+		return true
+	case word == "twice":
+		p.pos++
+		p.rule.Count = 2
+		return true
+	}
+
+	if a, ok := adverbs[word]; ok {
+		p.pos++
+		if word == "biweekly" {
+			p.ambiguous("%q is read as every 2 weeks, not twice a week", word)
+		}
+		p.setFrequency(a.freq, a.interval)
+		return true
+	}
+
+	if _, ok := weekdays[word]; ok || isWeekdayGroup(word) {
+		p.setWeekdays(p.parseWeekdays())
+		return true
+	}
+
+	if _, ok := p.parseTime(); ok {
+		return true
+	}
+
+	if n, ok := number(word); ok && (p.peek(1) == "times" || p.peek(1) == "occurrences") {
+		if n == 0 {
+			p.fail("%q has no occurrences", strings.Join(p.words[p.pos:p.pos+2], " "))
+		}
+		p.pos += 2
+		p.rule.Count = n
+		return true
+	}
+
+	if _, _, ok := p.ordinal(); ok {
+		return p.parsePositions()
+	}
+
+	if fillers[word] {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) setFrequency(freq rrule.FrequencyValue, interval int) {
+	if p.hasFrequency && (p.rule.Frequency != freq || p.rule.Interval != interval) {
+		p.ambiguous("conflicting frequencies, using every %d %s", interval, strings.ToLower(freq.String()))
+	}
+
+	p.hasFrequency = true
+	p.rule.Frequency = freq
+	p.rule.Interval = interval
+}
+
+// parseEvery reads "every other week", "every 3 days", "every Monday",
+// "every weekday" and "every June".
+func (p *parser) parseEvery() bool {
+	start := p.pos
+	p.pos++
+
+	interval := 1
+	if p.accept("other") {
+		interval = 2
+	} else if n, ok := number(p.peek(0)); ok {
+		if n == 0 {
+			p.fail("every 0 %s never repeats", p.peek(1))
+		}
+		p.pos++
+		interval = n
+	} else if n, ok := ordinalNumber(p.peek(0)); ok && n > 1 && isUnit(p.peek(1)) {
+		// every third week
+		p.pos++
+		interval = n
+	}
+
+	if _, length, ok := p.ordinal(); ok && interval == 1 {
+		// "every second Tuesday" and "every second and fourth Friday",
+		// "second" isn't the unit here.
+		if _, ok := weekdays[p.peek(length)]; ok || p.peek(length) == "and" {
+			return p.parsePositions()
+		}
+	}
+
+	word := p.peek(0)
+
+	switch {
+	case word == "fortnight":
+		p.pos++
+		p.setFrequency(rrule.WEEKLY, interval*2)
+		return true
+	case isUnit(word):
+		p.pos++
+		p.setFrequency(units[word], interval)
+		return true
+	case isMonth(word):
+		if !p.hasFrequency || interval > 1 {
+			p.setFrequency(rrule.YEARLY, interval)
+		}
+		p.setMonths(p.parseMonths())
+		return true
+	}
+
+	if _, ok := weekdays[word]; ok || isWeekdayGroup(word) {
+		if !p.hasFrequency || interval > 1 {
+			p.setFrequency(rrule.WEEKLY, interval)
+		}
+		p.setWeekdays(p.parseWeekdays())
+		return true
+	}
+
+	if _, _, ok := p.ordinal(); ok && interval == 1 {
+		// every last Friday of the month
+		return p.parsePositions()
+	}
+
+	p.pos = start
+	return false
+}
+
+func isUnit(word string) bool {
+	_, ok := units[word]
+	return ok
+}
+
+func isMonth(word string) bool {
+	_, ok := months[word]
+	return ok
+}
+
+func isWeekdayGroup(word string) bool {
+	switch word {
+	case "weekday", "weekdays", "weekend", "weekends":
+		return true
+	}
+	return false
+}
+
+func weekdayGroup(word string) []time.Weekday {
+	if strings.HasPrefix(word, "weekday") {
+		return workdays
+	}
+	if strings.HasPrefix(word, "weekend") {
+		return weekend
+	}
+	return []time.Weekday{weekdays[word]}
+}
+
+func number(word string) (int, bool) {
+	if n, ok := numberWords[word]; ok {
+		return n, true
+	}
+
+	n := 0
+	for _, r := range word {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n, word != ""
+}
+
+// ordinalNumber reads "third" and "3rd".
+func ordinalNumber(word string) (int, bool) {
+	if n, ok := ordinalWords[word]; ok {
+		return n, true
+	}
+
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if strings.HasSuffix(word, suffix) {
+			return number(strings.TrimSuffix(word, suffix))
+		}
+	}
+	return 0, false
+}
+
+// ordinal reads a position at the current word without consuming it,
+// "first", "3rd", "last", "second to last" and "next to last" count from
+// the end with negative values.
+func (p *parser) ordinal() (int, int, bool) {
+	word := p.peek(0)
+
+	switch {
+	case word == "last":
+		return -1, 1, true
+	case word == "penultimate":
+		return -2, 1, true
+	case word == "next" && p.peek(1) == "to" && p.peek(2) == "last":
+		return -2, 3, true
+	}
+
+	n, ok := ordinalNumber(word)
+	if !ok || n == 0 {
+		return 0, 0, false
+	}
+
+	if p.peek(1) == "to" && p.peek(2) == "last" {
+		return -n, 3, true
+	}
+	return n, 1, true
+}
+
+func (p *parser) parseMonths() []time.Month {
+	var results []time.Month
+
+	for {
+		m, ok := months[p.peek(0)]
+		if !ok {
+			break
+		}
+		p.pos++
+		results = append(results, m)
+
+		if p.peek(0) == "and" && isMonth(p.peek(1)) {
+			p.pos++
+		}
+	}
+
+	return results
+}
+
+func (p *parser) setMonths(values []time.Month) {
+	for _, m := range values {
+		p.rule.ByMonth = append(p.rule.ByMonth, int16(m))
+	}
+}
+
+func (p *parser) parseWeekdays() []time.Weekday {
+	var results []time.Weekday
+
+	for {
+		word := p.peek(0)
+		if _, ok := weekdays[word]; !ok && !isWeekdayGroup(word) {
+			break
+		}
+		p.pos++
+		results = append(results, weekdayGroup(word)...)
+
+		next := p.peek(1)
+		if _, ok := weekdays[next]; p.peek(0) == "and" && (ok || isWeekdayGroup(next)) {
+			p.pos++
+		}
+	}
+
+	return results
+}
+
+func (p *parser) setWeekdays(values []time.Weekday) {
+	for _, d := range values {
+		p.rule.ByDay = append(p.rule.ByDay, rrule.ForDay{Weekday: d})
+	}
+}
+
+// parsePositions reads a list of positions and what they count, "the first
+// Sunday and the second to last Friday", "the 1st and 15th", "the last
+// weekday of the month", "the 20th week of the year" or "only the last
+// occurrence in each month".
+func (p *parser) parsePositions() bool {
+	var list []position
+
+	for {
+		n, length, ok := p.ordinal()
+		if !ok {
+			break
+		}
+		p.pos += length
+
+		entry := position{n: n}
+		if d, ok := weekdays[p.peek(0)]; ok {
+			p.pos++
+			entry.weekday = d
+			entry.named = true
+		}
+		list = append(list, entry)
+
+		// "and the second", "and 15th"
+		save := p.pos
+		p.accept("and")
+		p.accept("the")
+		if _, _, ok := p.ordinal(); !ok {
+			p.pos = save
+			break
+		}
+	}
+
+	// "the first and third Thursday" share the weekday after them.
+	for i := len(list) - 2; i >= 0; i-- {
+		if !list[i].named && list[i+1].named {
+			list[i].weekday = list[i+1].weekday
+			list[i].named = true
+		}
+	}
+
+	noun := p.peek(0)
+	if list[len(list)-1].named {
+		noun = ""
+	} else if noun == "day" || noun == "days" || noun == "week" || noun == "weeks" ||
+		noun == "weekday" || noun == "weekdays" || noun == "occurrence" || noun == "occurrences" {
+		p.pos++
+	} else {
+		noun = ""
+	}
+
+	scope := p.parseScope()
+
+	for _, entry := range list {
+		switch {
+		case entry.named:
+			p.rule.ByDay = append(p.rule.ByDay, rrule.ForDay{Weekday: entry.weekday, Offset: entry.n})
+		case noun == "weekday" || noun == "weekdays":
+			p.rule.BySetPos = append(p.rule.BySetPos, int16(entry.n))
+		case noun == "occurrence" || noun == "occurrences":
+			p.rule.BySetPos = append(p.rule.BySetPos, int16(entry.n))
+		case noun == "week" || noun == "weeks":
+			p.rule.ByWeekNo = append(p.rule.ByWeekNo, int16(entry.n))
+		case scope == rrule.YEARLY:
+			p.rule.ByYearDay = append(p.rule.ByYearDay, int16(entry.n))
+		default:
+			p.rule.ByMonthDay = append(p.rule.ByMonthDay, int16(entry.n))
+		}
+	}
+
+	if noun == "weekday" || noun == "weekdays" {
+		p.setWeekdays(workdays)
+	}
+
+	return true
+}
+
+// parseScope reads the period positions count in, "of the month", "of
+// every month" or "in each year". It returns MONTHLY when there is none.
+func (p *parser) parseScope() rrule.FrequencyValue {
+	if p.peek(0) != "of" && p.peek(0) != "in" {
+		return rrule.MONTHLY
+	}
+
+	next := p.peek(1)
+	if (next == "every" || next == "each") && p.hasFrequency && isUnit(p.peek(2)) && units[p.peek(2)] == p.rule.Frequency {
+		// "only the last occurrence in each month" of a rule that is
+		// already monthly.
+		p.pos += 3
+		return p.rule.Frequency
+	}
+	if next == "every" || next == "each" {
+		p.pos++
+		if p.parseEvery() && (p.rule.Frequency == rrule.YEARLY || p.rule.Frequency == rrule.MONTHLY) {
+			return p.rule.Frequency
+		}
+		return rrule.MONTHLY
+	}
+
+	if (next == "the" || next == "a") && isUnit(p.peek(2)) {
+		freq := units[p.peek(2)]
+		p.pos += 3
+		if !p.hasFrequency {
+			p.setFrequency(freq, 1)
+		}
+		return freq
+	}
+
+	return rrule.MONTHLY
+}
+
+// parseAt reads "at 9am and 5pm" as well as "at hours 9 and 17".
+func (p *parser) parseAt() bool {
+	p.pos++
+
+	var target *[]int16
+	switch p.peek(0) {
+	case "hour", "hours":
+		target = &p.rule.ByHour
+	case "minute", "minutes":
+		target = &p.rule.ByMinute
+	case "second", "seconds":
+		target = &p.rule.BySecond
+	}
+
+	if target != nil {
+		p.pos++
+		for {
+			n, ok := number(p.peek(0))
+			if !ok {
+				break
+			}
+			p.pos++
+			*target = append(*target, int16(n))
+
+			if _, ok := number(p.peek(1)); p.peek(0) == "and" && ok {
+				p.pos++
+			}
+		}
+		return true
+	}
+
+	for {
+		if _, ok := p.parseTime(); !ok {
+			break
+		}
+
+		if p.peek(0) == "and" && p.looksLikeTime(1) {
+			p.pos++
+		}
+	}
+
+	return true
+}
+
+func (p *parser) looksLikeTime(offset int) bool {
+	save := p.pos
+	p.pos += offset
+	_, ok := p.readTime()
+	p.pos = save
+	return ok
+}
+
+// parseTime reads a time of day and adds it to the times of the rule.
+func (p *parser) parseTime() (clock, bool) {
+	start := p.pos
+	c, ok := p.readTime()
+	if !ok {
+		return c, false
+	}
+
+	p.times = append(p.times, c)
+	if word := p.words[start]; p.pos == start+1 && c.hour < 12 && !strings.ContainsAny(word, ":amp") {
+		p.ambiguous("%q is read as %02d:00, not %02d:00", word, c.hour, c.hour+12)
+	}
+	return c, true
+}
+
+// readTime reads "9am", "9 pm", "9:30", "17:00", "noon" and "midnight". A
+// bare number is only read as an hour after "at".
+func (p *parser) readTime() (clock, bool) {
+	word := p.peek(0)
+
+	switch word {
+	case "noon", "midday":
+		p.pos++
+		return clock{12, 0}, true
+	case "midnight":
+		p.pos++
+		return clock{0, 0}, true
+	}
+
+	suffix := ""
+	for _, s := range []string{"am", "pm"} {
+		if strings.HasSuffix(word, s) {
+			suffix = s
+			word = strings.TrimSuffix(word, s)
+		}
+	}
+
+	length := 1
+	if suffix == "" && (p.peek(1) == "am" || p.peek(1) == "pm") {
+		suffix = p.peek(1)
+		length = 2
+	}
+
+	hourText, minuteText, hasMinutes := strings.Cut(word, ":")
+
+	hour, ok := number(hourText)
+	if !ok {
+		return clock{}, false
+	}
+
+	minute := 0
+	if hasMinutes {
+		if minute, ok = number(minuteText); !ok || len(minuteText) != 2 || minute > 59 {
+			return clock{}, false
+		}
+	}
+
+	if suffix == "" && !hasMinutes {
+		// A bare number is only a time right after "at".
+		previous := ""
+		if p.pos > 0 {
+			previous = p.words[p.pos-1]
+		}
+		if previous != "at" && !(previous == "and" && len(p.times) > 0) {
+			return clock{}, false
+		}
+		if next := p.peek(1); next == "times" || next == "occurrences" || isUnit(next) {
+			return clock{}, false
+		}
+	}
+
+	switch suffix {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return clock{}, false
+		}
+		hour = hour % 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return clock{}, false
+		}
+	}
+
+	p.pos += length
+	return clock{hour, minute}, true
+}
+
+// parseUntil reads "until June", "until December 24, 1997", "through
+// March" and "ending on 2024-06-01". Days end at midnight, a month is read
+// as up to its start after "until" and up to its end after "through".
+func (p *parser) parseUntil() bool {
+	start := p.pos
+	inclusive := p.peek(0) == "through"
+	p.pos++
+	p.accept("on")
+
+	loc := p.reference.Location()
+
+	if year, ok := p.parseYear(); ok {
+		date := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+		if inclusive {
+			date = date.AddDate(1, 0, 0)
+		} else {
+			p.ambiguous("%q is read as before %d, not through the end of it",
+				strings.Join(p.words[start:p.pos], " "), year)
+		}
+		p.rule.Until = date.Add(-time.Second)
+		return true
+	}
+
+	date, hasDay, ok := p.parseDate()
+	if !ok {
+		p.fail("no date after %q", p.words[start])
+		p.pos = start
+		return false
+	}
+
+	switch {
+	case hasDay:
+		date = time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, loc)
+	case inclusive:
+		date = time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, loc)
+	default:
+		p.ambiguous("%q is read as before %s, not through the end of it",
+			strings.Join(p.words[start:p.pos], " "), date.Format("January 2006"))
+	}
+
+	// The last second of the day before.
+	p.rule.Until = date.Add(-time.Second)
+	return true
+}
+
+func (p *parser) parseStart() bool {
+	start := p.pos
+	p.pos++
+	p.accept("on", "from")
+
+	if year, ok := p.parseYear(); ok {
+		p.ambiguous("%q is read as the start of %d", strings.Join(p.words[start:p.pos], " "), year)
+		p.start = time.Date(year, time.January, 1, 0, 0, 0, 0, p.reference.Location())
+		p.hasStart = true
+		return true
+	}
+
+	date, hasDay, ok := p.parseDate()
+	if !ok {
+		p.pos = start
+		return false
+	}
+
+	if !hasDay {
+		p.ambiguous("%q is read as the start of %s",
+			strings.Join(p.words[start:p.pos], " "), date.Format("January 2006"))
+	}
+
+	p.start = date
+	p.hasStart = true
+	return true
+}
+
+// parseYear reads a year on its own, as in "until 2027".
+func (p *parser) parseYear() (int, bool) {
+	if n, ok := number(p.peek(0)); ok && len(p.peek(0)) == 4 && !isMonth(p.peek(1)) {
+		p.pos++
+		return n, true
+	}
+	return 0, false
+}
+
+// parseDate reads "today", "tomorrow", "June", "June 5th", "June 5 2025",
+// "5 June", "the 5th of June" and "2025-06-05". Without a year the date is
+// the next one on or after the reference.
+func (p *parser) parseDate() (time.Time, bool, bool) {
+	loc := p.reference.Location()
+	today := time.Date(p.reference.Year(), p.reference.Month(), p.reference.Day(), 0, 0, 0, 0, loc)
+
+	switch p.peek(0) {
+	case "today":
+		p.pos++
+		return today, true, true
+	case "tomorrow":
+		p.pos++
+		return today.AddDate(0, 0, 1), true, true
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", p.peek(0), loc); err == nil {
+		p.pos++
+		return t, true, true
+	}
+
+	start := p.pos
+	day := 0
+
+	// "5 June" and "the 5th of June"
+	if p.peek(0) == "the" {
+		p.pos++
+	}
+	if n, ok := dayNumber(p.peek(0)); ok {
+		p.pos++
+		p.accept("of")
+		day = n
+	}
+
+	month, ok := months[p.peek(0)]
+	if !ok {
+		p.pos = start
+		return time.Time{}, false, false
+	}
+	p.pos++
+
+	if day == 0 {
+		if n, ok := dayNumber(p.peek(0)); ok {
+			p.pos++
+			day = n
+		}
+	}
+
+	year := 0
+	if n, ok := number(p.peek(0)); ok && len(p.peek(0)) == 4 {
+		p.pos++
+		year = n
+	}
+
+	if day > 31 {
+		p.pos = start
+		return time.Time{}, false, false
+	}
+
+	hasDay := day > 0
+	if !hasDay {
+		day = 1
+	}
+
+	if year == 0 {
+		year = today.Year()
+		last := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if !hasDay {
+			last = last.AddDate(0, 1, -1)
+		}
+		if last.Before(today) {
+			year++
+		}
+
+		// February 29th is in the next leap year.
+		for i := 0; i < 8 && month == time.February && !dateExists(year, month, day); i++ {
+			year++
+		}
+	}
+
+	if !dateExists(year, month, day) {
+		p.fail("%s %d, %d doesn't exist", month, day, year)
+		p.pos = start
+		return time.Time{}, false, false
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, loc), hasDay, true
+}
+
+func dateExists(year int, month time.Month, day int) bool {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Day() == day
+}
+
+func dayNumber(word string) (int, bool) {
+	if n, ok := ordinalNumber(word); ok && n >= 1 && n <= 31 && !isOrdinalWord(word) {
+		return n, true
+	}
+	if _, ok := numberWords[word]; ok {
+		return 0, false
+	}
+	if n, ok := number(word); ok && n >= 1 && n <= 31 {
+		return n, true
+	}
+	return 0, false
+}
+
+func isOrdinalWord(word string) bool {
+	_, ok := ordinalWords[word]
+	return ok
+}
+
+// parseWeekStart reads "with weeks starting on Sunday".
+func (p *parser) parseWeekStart() bool {
+	start := p.pos
+	p.pos++
+
+	if p.accept("weeks", "the") && p.accept("starting", "week", "beginning") {
+		p.accept("starting", "beginning", "on")
+		p.accept("on")
+		if d, ok := weekdays[p.peek(0)]; ok {
+			p.pos++
+			p.rule.WorkWeekStart = d
+			return true
+		}
+	}
+
+	p.pos = start
+	return false
+}
+
+// finish fills in the frequency when the phrase only implies it, applies
+// the times, checks the rule and moves DtStart to the first occurrence.
+func (p *parser) finish() error {
+	rr := p.rule
+
+	if !p.hasFrequency {
+		hasOffsets := false
+		for _, fd := range rr.ByDay {
+			if fd.Offset != 0 {
+				hasOffsets = true
+			}
+		}
+
+		switch {
+		case len(rr.ByMonth) > 0 || len(rr.ByWeekNo) > 0 || len(rr.ByYearDay) > 0:
+			rr.Frequency = rrule.YEARLY
+		case hasOffsets || len(rr.ByMonthDay) > 0 || len(rr.BySetPos) > 0:
+			rr.Frequency = rrule.MONTHLY
+		case len(rr.ByDay) > 0:
+			rr.Frequency = rrule.WEEKLY
+		case len(p.times) > 0:
+			rr.Frequency = rrule.DAILY
+			p.ambiguous("no frequency given, assuming every day")
+		default:
+			return errors.New("no recurrence found in phrase")
+		}
+	}
+
+	loc := p.reference.Location()
+	day := p.reference
+	if p.hasStart {
+		day = p.start
+	}
+
+	hour, minute := p.reference.Hour(), p.reference.Minute()
+	if len(p.times) > 0 {
+		hour, minute = p.times[0].hour, p.times[0].minute
+	}
+
+	if len(p.times) > 1 {
+		minutes := map[int]bool{}
+		for _, c := range p.times {
+			rr.ByHour = appendUnique(rr.ByHour, c.hour)
+			minutes[c.minute] = true
+			rr.ByMinute = appendUnique(rr.ByMinute, c.minute)
+		}
+		if len(minutes) > 1 {
+			p.ambiguous("every hour is combined with every minute of the given times")
+		}
+	}
+
+	rr.DtStart = time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+
+	if err := rr.Validate(); err != nil {
+		return err
+	}
+
+	// The first occurrence is searched from the reference, or from the
+	// start of the day the phrase starts on. The interval is left out so
+	// "every other Tuesday" starts on the next Tuesday.
+	from := p.reference
+	if p.hasStart {
+		from = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	}
+
+	search := *rr
+	search.Interval = 1
+	search.Count = 0
+
+	var event time.Time
+	iter := search.Iterator().HardLimit(searchLimit)
+	for iter.Step(&event) {
+		if !event.Before(from) {
+			rr.DtStart = event
+			return nil
+		}
+	}
+
+	p.ambiguous("no occurrence found after %s", from.Format(time.RFC3339))
+	return nil
+}
+
+func appendUnique(values []int16, v int) []int16 {
+	for _, existing := range values {
+		if int(existing) == v {
+			return values
+		}
+	}
+	return append(values, int16(v))
+}
@@ -0,0 +1,200 @@
+package phrase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/graham/rrule"
+)
+
+// A Wednesday afternoon.
+var reference = time.Date(2026, time.October, 14, 15, 30, 0, 0, time.UTC)
+
+func Test_Parse(t *testing.T) {
+	var cases = []struct {
+		phrase   string
+		expected string
+	}{
+		{
+			"every other Tuesday at 9am until June",
+			"DTSTART;TZID=UTC:20261020T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;UNTIL=20270531T235959Z",
+		},
+		{
+			"last Friday of every month",
+			"DTSTART;TZID=UTC:20261030T153000Z\nRRULE:FREQ=MONTHLY;BYDAY=-1FR",
+		},
+		{
+			"every weekday at 8:30",
+			"DTSTART;TZID=UTC:20261015T083000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		},
+		{
+			"the first and third Thursday of each month",
+			"DTSTART;TZID=UTC:20261015T153000Z\nRRULE:FREQ=MONTHLY;BYDAY=1TH,3TH",
+		},
+		{
+			"on the 1st and 15th of every month, 6 times",
+			"DTSTART;TZID=UTC:20261015T153000Z\nRRULE:FREQ=MONTHLY;BYMONTHDAY=1,15;COUNT=6",
+		},
+		{
+			"the last weekday of the month",
+			"DTSTART;TZID=UTC:20261030T153000Z\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		},
+		{
+			"daily at 9am and 5pm",
+			"DTSTART;TZID=UTC:20261014T170000Z\nRRULE:FREQ=DAILY;BYHOUR=9,17;BYMINUTE=0",
+		},
+		{
+			"every year on the 5th of June",
+			"DTSTART;TZID=UTC:20270605T153000Z\nRRULE:FREQ=YEARLY;BYMONTH=6;BYMONTHDAY=5",
+		},
+		{
+			"Mondays and Thursdays at noon starting November 2",
+			"DTSTART;TZID=UTC:20261102T120000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,TH",
+		},
+		{
+			"every third week on Monday until December 24, 2027",
+			"DTSTART;TZID=UTC:20261019T153000Z\nRRULE:FREQ=WEEKLY;INTERVAL=3;BYDAY=MO;UNTIL=20271224T235959Z",
+		},
+		{
+			"Every year in January and March on the first Sunday and the second to last Friday, once",
+			"DTSTART;TZID=UTC:20270103T153000Z\nRRULE:FREQ=YEARLY;BYMONTH=1,3;BYDAY=1SU,-2FR;COUNT=1",
+		},
+		{
+			"every second Tuesday",
+			"DTSTART;TZID=UTC:20261110T153000Z\nRRULE:FREQ=MONTHLY;BYDAY=2TU",
+		},
+		{
+			"every second and fourth Friday at 10am",
+			"DTSTART;TZID=UTC:20261023T100000Z\nRRULE:FREQ=MONTHLY;BYDAY=2FR,4FR",
+		},
+		{
+			"every Monday until 2028",
+			"DTSTART;TZID=UTC:20261019T153000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20271231T235959Z",
+		},
+		{
+			"every Monday through 2027",
+			"DTSTART;TZID=UTC:20261019T153000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20271231T235959Z",
+		},
+		{
+			"every year starting February 29",
+			"DTSTART;TZID=UTC:20280229T153000Z\nRRULE:FREQ=YEARLY",
+		},
+	}
+
+	for _, c := range cases {
+		result, err := Parse(c.phrase, reference)
+		if err != nil {
+			t.Log("Failed to parse", c.phrase, err)
+			t.Fail()
+			continue
+		}
+
+		expected, _ := rrule.Parse(c.expected)
+		if result.Rule.String() != expected.String() {
+			t.Log("Unexpected rule for", c.phrase)
+			t.Log(result.Rule)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Parse_Confidence(t *testing.T) {
+	var cases = []struct {
+		phrase      string
+		ambiguities int
+		ignored     int
+	}{
+		{"last Friday of every month", 0, 0},
+		{"every other Tuesday at 9am until June", 1, 0},
+		{"biweekly on Friday", 1, 0},
+		{"every day at 5", 1, 0},
+		{"every day at 9am for lunch", 0, 1},
+		{"every 2 weeks, except on 3 dates", 1, 0},
+		{"every second Tuesday", 0, 0},
+		{"every Monday until 2028", 1, 0},
+	}
+
+	for _, c := range cases {
+		result, err := Parse(c.phrase, reference)
+		if err != nil {
+			t.Log("Failed to parse", c.phrase, err)
+			t.Fail()
+			continue
+		}
+
+		if len(result.Ambiguities) != c.ambiguities || len(result.Ignored) != c.ignored {
+			t.Log("Unexpected ambiguities", c.phrase, result.Ambiguities, result.Ignored)
+			t.Fail()
+		}
+
+		if (result.Confidence == 1) != (c.ambiguities == 0 && c.ignored == 0) {
+			t.Log("Unexpected confidence", c.phrase, result.Confidence)
+			t.Fail()
+		}
+	}
+
+	var invalid = []string{
+		"",
+		"lunch with Sam",
+		"every 0 days",
+		"every day, 0 times",
+		"every day starting June 31",
+		"every week starting February 29, 2027",
+		"every Monday until 2025",
+		"every Monday until whenever",
+		"on the 32nd of every month",
+	}
+
+	for _, phrase := range invalid {
+		if _, err := Parse(phrase, reference); err == nil {
+			t.Log("Expected error for", phrase)
+			t.Fail()
+		}
+	}
+}
+
+// Describing a parsed phrase and parsing the description gives the same
+// rule, apart from the time of DtStart which isn't described.
+func Test_Parse_RoundTrip(t *testing.T) {
+	var phrases = []string{
+		"every other Tuesday at 9am until June",
+		"last Friday of every month",
+		"every weekday",
+		"every 3 days, 10 times",
+		"on the 1st and last day of every month",
+		"the first and third Thursday of each month",
+		"the last weekday of the month",
+		"daily at 9am and 5pm",
+		"every year on the 5th of June",
+		"every Monday, Wednesday and Friday until December 24, 2027",
+		"every 4 hours",
+	}
+
+	for _, phrase := range phrases {
+		first, err := Parse(phrase, reference)
+		if err != nil {
+			t.Fatal(phrase, err)
+		}
+
+		description := first.Rule.Describe(rrule.English)
+
+		second, err := Parse(description, reference)
+		if err != nil {
+			t.Log("Failed to parse description", description, err)
+			t.Fail()
+			continue
+		}
+
+		second.Rule.DtStart = first.Rule.DtStart
+		if second.Rule.String() != first.Rule.String() || len(second.Ignored) > 0 {
+			t.Log("Round trip failed", phrase, description)
+			t.Log(second.Rule, second.Ignored)
+			t.Fail()
+		}
+
+		if again := second.Rule.Describe(rrule.English); again != description {
+			t.Log("Descriptions don't match", description, again)
+			t.Fail()
+		}
+	}
+}
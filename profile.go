@@ -0,0 +1,258 @@
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Calendar clients only accept a subset of RRULE. A Profile describes the
+// subset of one client, so a rule can be checked before it's pushed, and
+// rewritten into an equivalent form the client accepts where there is one.
+
+// Incompatibility is a part of a rule that a client doesn't accept.
+type Incompatibility struct {
+	Part   string
+	Reason string
+}
+
+func (i Incompatibility) String() string {
+	return fmt.Sprintf("%s: %s", i.Part, i.Reason)
+}
+
+type Profile struct {
+	Name string
+
+	check func(rr *RecurringRule, report func(part, reason string))
+
+	// Rewrites must keep the occurrences of the rule the same.
+	rewrites []func(rr *RecurringRule)
+}
+
+var GoogleProfile = &Profile{
+	Name:     "google",
+	check:    checkGoogle,
+	rewrites: []func(*RecurringRule){rewriteImpliedTimes, rewriteSetPosToOffset, rewriteUntilToUTC},
+}
+
+var OutlookProfile = &Profile{
+	Name:     "outlook",
+	check:    checkOutlook,
+	rewrites: []func(*RecurringRule){rewriteImpliedTimes, rewriteSetPosToOffset},
+}
+
+var AppleProfile = &Profile{
+	Name:     "apple",
+	check:    checkApple,
+	rewrites: []func(*RecurringRule){rewriteImpliedTimes, rewriteUntilToUTC},
+}
+
+var profiles = map[string]*Profile{
+	GoogleProfile.Name:  GoogleProfile,
+	OutlookProfile.Name: OutlookProfile,
+	AppleProfile.Name:   AppleProfile,
+}
+
+// LookupProfile returns the profile named "google", "outlook" or "apple".
+func LookupProfile(name string) (*Profile, bool) {
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// Check returns every part of the rule that the client doesn't accept, an
+// empty result means the rule can be used as is.
+func (p *Profile) Check(rr *RecurringRule) []Incompatibility {
+	var results []Incompatibility
+
+	p.check(rr, func(part, reason string) {
+		results = append(results, Incompatibility{Part: part, Reason: reason})
+	})
+
+	return results
+}
+
+// Rewrite returns a copy of the rule with every rewrite the client needs
+// applied, along with the incompatibilities that are left.
+func (p *Profile) Rewrite(rr *RecurringRule) (*RecurringRule, []Incompatibility) {
+	result := rr.Clone()
+
+	for _, rewrite := range p.rewrites {
+		rewrite(result)
+	}
+
+	return result, p.Check(result)
+}
+
+func checkFrequency(rr *RecurringRule, report func(part, reason string)) {
+	if rr.Frequency > DAILY {
+		report("FREQ", fmt.Sprintf("%s is not supported, only DAILY and coarser", rr.Frequency))
+	}
+}
+
+func checkTimes(rr *RecurringRule, report func(part, reason string)) {
+	parts := []struct {
+		name   string
+		values []int16
+	}{
+		{"BYHOUR", rr.ByHour},
+		{"BYMINUTE", rr.ByMinute},
+		{"BYSECOND", rr.BySecond},
+	}
+
+	for _, part := range parts {
+		if len(part.values) > 0 {
+			report(part.name, "every occurrence must be at the time of DTSTART")
+		}
+	}
+}
+
+func checkUntilUTC(rr *RecurringRule, report func(part, reason string)) {
+	if !rr.Until.Equal(EmptyTime) && rr.Until.Location() != time.UTC {
+		report("UNTIL", "must be in UTC")
+	}
+}
+
+func checkGoogle(rr *RecurringRule, report func(part, reason string)) {
+	checkFrequency(rr, report)
+	checkTimes(rr, report)
+	checkUntilUTC(rr, report)
+
+	if len(rr.BySetPos) > 0 && (rr.Frequency != MONTHLY || len(rr.BySetPos) > 1 || len(rr.ByDay) == 0) {
+		report("BYSETPOS", "only a single position in a MONTHLY rule with BYDAY is supported")
+	}
+}
+
+// checkOutlook matches what Graph and MAPI can represent, see
+// GraphPatternedRecurrence.
+func checkOutlook(rr *RecurringRule, report func(part, reason string)) {
+	checkFrequency(rr, report)
+	checkTimes(rr, report)
+
+	if len(rr.ByYearDay) > 0 {
+		report("BYYEARDAY", "is not supported")
+	}
+	if len(rr.ByWeekNo) > 0 {
+		report("BYWEEKNO", "is not supported")
+	}
+
+	switch rr.Frequency {
+	case DAILY, WEEKLY:
+		if len(rr.ByMonthDay) > 0 {
+			report("BYMONTHDAY", "is only supported in MONTHLY and YEARLY rules")
+		}
+		if len(rr.ByMonth) > 0 {
+			report("BYMONTH", "is only supported in YEARLY rules")
+		}
+		if len(rr.BySetPos) > 0 {
+			report("BYSETPOS", "is only supported in MONTHLY and YEARLY rules")
+		}
+		if rr.Frequency == DAILY && len(rr.ByDay) > 0 && rr.Interval > 1 {
+			report("BYDAY", "a DAILY rule with BYDAY must have an INTERVAL of 1")
+		}
+		for _, fd := range rr.ByDay {
+			if fd.Offset != 0 {
+				report("BYDAY", "offsets are only supported in MONTHLY and YEARLY rules")
+				break
+			}
+		}
+	case MONTHLY, YEARLY:
+		var month int
+
+		if rr.Frequency == MONTHLY && len(rr.ByMonth) > 0 {
+			report("BYMONTH", "is only supported in YEARLY rules")
+		}
+		if rr.Frequency == YEARLY {
+			month = int(rr.DtStart.Month())
+
+			switch {
+			case len(rr.ByMonth) > 1:
+				report("BYMONTH", "only a single month is supported")
+			case len(rr.ByMonth) == 1:
+				month = int(rr.ByMonth[0])
+			case len(rr.ByDay) > 0:
+				report("BYMONTH", "is required with BYDAY in a YEARLY rule")
+			}
+		}
+
+		ce := &ConversionError{}
+
+		if len(rr.ByDay) > 0 {
+			if len(rr.ByMonthDay) > 0 {
+				report("BYMONTHDAY", "can't be combined with BYDAY")
+			}
+
+			_, offset := rr.relativeDays(ce)
+			if offset != -1 && (offset < 1 || offset > 4) {
+				ce.Add("only the first four or the last day are supported")
+			}
+
+			for _, problem := range ce.Problems {
+				report("BYDAY", problem)
+			}
+		} else {
+			// Outlook moves days past the end of shorter months.
+			rr.clampedMonthDay(month, ce)
+
+			for _, problem := range ce.Problems {
+				report("BYMONTHDAY", problem)
+			}
+		}
+	}
+}
+
+func checkApple(rr *RecurringRule, report func(part, reason string)) {
+	checkFrequency(rr, report)
+	checkTimes(rr, report)
+	checkUntilUTC(rr, report)
+}
+
+// rewriteImpliedTimes drops BYHOUR, BYMINUTE and BYSECOND when they only
+// repeat the time of DtStart.
+func rewriteImpliedTimes(rr *RecurringRule) {
+	if rr.Frequency > DAILY {
+		return
+	}
+
+	if len(rr.ByHour) == 1 && int(rr.ByHour[0]) == rr.DtStart.Hour() {
+		rr.ByHour = nil
+	}
+	if len(rr.ByMinute) == 1 && int(rr.ByMinute[0]) == rr.DtStart.Minute() {
+		rr.ByMinute = nil
+	}
+	if len(rr.BySecond) == 1 && int(rr.BySecond[0]) == rr.DtStart.Second() {
+		rr.BySecond = nil
+	}
+}
+
+// rewriteSetPosToOffset turns BYDAY=MO;BYSETPOS=2 into BYDAY=2MO. It's
+// only the same when the weekday is the only filter within a single month,
+// or within the year.
+func rewriteSetPosToOffset(rr *RecurringRule) {
+	if rr.Frequency != MONTHLY && rr.Frequency != YEARLY {
+		return
+	}
+
+	if len(rr.ByDay) != 1 || rr.ByDay[0].Offset != 0 || len(rr.BySetPos) != 1 {
+		return
+	}
+
+	if len(rr.ByMonthDay) > 0 || len(rr.ByYearDay) > 0 || len(rr.ByWeekNo) > 0 || len(rr.ByMonth) > 1 {
+		return
+	}
+
+	if rr.Frequency == MONTHLY && len(rr.ByMonth) > 0 {
+		return
+	}
+
+	if len(rr.ByHour) > 0 || len(rr.ByMinute) > 0 || len(rr.BySecond) > 0 {
+		return
+	}
+
+	rr.ByDay[0].Offset = int(rr.BySetPos[0])
+	rr.BySetPos = nil
+}
+
+func rewriteUntilToUTC(rr *RecurringRule) {
+	if !rr.Until.Equal(EmptyTime) {
+		rr.Until = rr.Until.UTC()
+	}
+}
@@ -0,0 +1,116 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func incompatibleParts(results []Incompatibility) map[string]bool {
+	found := map[string]bool{}
+	for _, i := range results {
+		found[i.Part] = true
+	}
+	return found
+}
+
+func Test_Profile_Check(t *testing.T) {
+	var cases = []struct {
+		profile  *Profile
+		rule     string
+		expected []string
+	}{
+		{GoogleProfile, "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", nil},
+		{GoogleProfile, "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1", []string{"BYSETPOS"}},
+		{GoogleProfile, "RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=MO;BYSETPOS=2", []string{"BYSETPOS"}},
+		{GoogleProfile, "RRULE:FREQ=HOURLY;INTERVAL=4", []string{"FREQ"}},
+		{OutlookProfile, "RRULE:FREQ=DAILY;BYHOUR=9,17", []string{"BYHOUR"}},
+		{OutlookProfile, "RRULE:FREQ=MONTHLY;BYDAY=-1FR", nil},
+		{OutlookProfile, "RRULE:FREQ=MONTHLY;BYDAY=5FR", []string{"BYDAY"}},
+		{OutlookProfile, "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,15", []string{"BYMONTHDAY"}},
+		{OutlookProfile, "RRULE:FREQ=MONTHLY;BYMONTHDAY=31", []string{"BYMONTHDAY"}},
+		{OutlookProfile, "RRULE:FREQ=YEARLY;BYMONTH=1,7;BYWEEKNO=2", []string{"BYMONTH", "BYWEEKNO"}},
+		{OutlookProfile, "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", nil},
+		{AppleProfile, "RRULE:FREQ=WEEKLY;UNTIL=19971224T000000", []string{"UNTIL"}},
+		{AppleProfile, "RRULE:FREQ=WEEKLY;UNTIL=19971224T000000Z", nil},
+	}
+
+	for _, c := range cases {
+		rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\n" + c.rule)
+
+		found := incompatibleParts(c.profile.Check(rule))
+		if len(found) != len(c.expected) {
+			t.Log("Unexpected incompatibilities", c.profile.Name, c.rule, c.profile.Check(rule))
+			t.Fail()
+			continue
+		}
+
+		for _, part := range c.expected {
+			if !found[part] {
+				t.Log("Missing incompatibility", c.profile.Name, c.rule, part)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func Test_Profile_Rewrite(t *testing.T) {
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:19970902T090000\n" +
+			"RRULE:FREQ=MONTHLY;BYDAY=TU;BYSETPOS=-1;BYHOUR=9;UNTIL=19971224T000000",
+	)
+
+	for _, name := range []string{"google", "outlook", "apple"} {
+		profile, ok := LookupProfile(name)
+		if !ok {
+			t.Fatal("Missing profile", name)
+		}
+
+		result, remaining := profile.Rewrite(rule)
+		if len(remaining) != 0 {
+			t.Log("Rewrite left incompatibilities", name, remaining, result)
+			t.Fail()
+		}
+
+		// The rewritten rule must have the same occurrences.
+		sameOccurrences(t, rule.Iterator(), result.Iterator(), name)
+	}
+
+	if len(rule.ByHour) != 1 || len(rule.BySetPos) != 1 || rule.Until.Location() == time.UTC {
+		t.Log("Rewrite changed the original rule", rule)
+		t.Fail()
+	}
+
+	// Several hours can't be rewritten.
+	rule, _ = Parse("DTSTART;TZID=UTC:19970902T090000Z\nRRULE:FREQ=DAILY;BYHOUR=9,17")
+	if _, remaining := OutlookProfile.Rewrite(rule); len(remaining) != 1 {
+		t.Log("Expected BYHOUR to remain", remaining)
+		t.Fail()
+	}
+
+	if _, ok := LookupProfile("lotus"); ok {
+		t.Log("Unexpected profile")
+		t.Fail()
+	}
+}
+
+func Test_Profile_OutlookMatchesGraph(t *testing.T) {
+	var rules = []string{
+		"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=28,29,30,31;BYSETPOS=-1",
+		"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+		"RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=30",
+		"RRULE:FREQ=YEARLY;BYDAY=1MO",
+		"RRULE:FREQ=DAILY;INTERVAL=2;BYDAY=MO",
+	}
+
+	for _, value := range rules {
+		rule, _ := Parse("DTSTART;TZID=UTC:19970902T090000Z\n" + value)
+
+		_, err := rule.GraphPatternedRecurrence()
+		if (err == nil) != (len(OutlookProfile.Check(rule)) == 0) {
+			t.Log("Outlook profile and Graph conversion disagree", value, err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quartz cron expressions have the fields
+//
+//	second minute hour day-of-month month day-of-week [year]
+//
+// Exactly one of day-of-month and day-of-week must be "?". On top of the
+// cron syntax Quartz adds L (last), W (nearest weekday) and # (nth
+// weekday), and numbers the days of the week from 1 (Sunday) to 7.
+
+var quartzDayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+var workWeek = []ForDay{
+	{Weekday: time.Monday},
+	{Weekday: time.Tuesday},
+	{Weekday: time.Wednesday},
+	{Weekday: time.Thursday},
+	{Weekday: time.Friday},
+}
+
+// ParseQuartz converts a Quartz cron expression into a RuleSet starting on
+// the day of from. Most expressions become a single rule, "nW" needs three.
+func ParseQuartz(expr string, from time.Time) (RuleSet, error) {
+	fields := strings.Fields(expr)
+
+	if len(fields) == 6 {
+		fields = append(fields, "*")
+	} else if len(fields) != 7 {
+		return nil, BadFormatError(expr)
+	}
+
+	return parseQuartzFields(fields, from, "Quartz")
+}
+
+func parseQuartzWeekday(s string) (time.Weekday, error) {
+	value, err := parseCronValue(s, quartzDayNames)
+	if err != nil || value < 1 || value > 7 {
+		return time.Sunday, BadFormatError(s)
+	}
+	return time.Weekday(value - 1), nil
+}
+
+// parseQuartzFields is shared with the EventBridge parser, fields are
+// second, minute, hour, day-of-month, month, day-of-week and year.
+func parseQuartzFields(fields []string, from time.Time, format string) (RuleSet, error) {
+	ce := &ConversionError{Format: format}
+
+	seconds, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	minutes, err := parseCronField(fields[1], 0, 59, nil)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := parseCronField(fields[2], 0, 23, nil)
+	if err != nil {
+		return nil, err
+	}
+	months, err := parseCronField(fields[4], 1, 12, cronMonthNames)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &RecurringRule{
+		DtStart: time.Date(
+			from.Year(), from.Month(), from.Day(),
+			int(hours[0]), int(minutes[0]), int(seconds[0]),
+			0, from.Location(),
+		),
+		Frequency:     DAILY,
+		Interval:      1,
+		WorkWeekStart: time.Monday,
+		BySecond:      seconds,
+		ByMinute:      minutes,
+		ByHour:        hours,
+	}
+
+	if !isFullRange(months, 1, 12) {
+		base.ByMonth = months
+	}
+
+	singleTime := len(seconds)*len(minutes)*len(hours) == 1
+
+	if err := quartzYearInto(base, fields[6]); err != nil {
+		ce.Add("%s", err)
+	}
+
+	dom, dow := strings.ToUpper(fields[3]), strings.ToUpper(fields[5])
+
+	if (dom == "?") == (dow == "?") {
+		ce.Add("exactly one of day-of-month and day-of-week must be ?")
+		return nil, ce
+	}
+
+	var rules = RuleSet{base}
+
+	if dow == "?" {
+		switch {
+		case dom == "L":
+			base.ByMonthDay = []int16{-1}
+		case strings.HasPrefix(dom, "L-"):
+			offset, err := strconv.Atoi(dom[2:])
+			if err != nil || offset < 0 || offset > 30 {
+				return nil, BadFormatError(dom)
+			}
+			base.ByMonthDay = []int16{int16(-offset - 1)}
+		case dom == "LW":
+			if !singleTime {
+				ce.Add("LW with more than one time of day can not be represented")
+			}
+			base.Frequency = MONTHLY
+			base.ByDay = append([]ForDay(nil), workWeek...)
+			base.BySetPos = []int16{-1}
+		case strings.HasSuffix(dom, "W"):
+			day, err := strconv.Atoi(dom[:len(dom)-1])
+			if err != nil || day < 1 || day > 31 {
+				return nil, BadFormatError(dom)
+			}
+			if day >= 28 {
+				ce.Add("%s may move into another month and can not be represented", dom)
+				break
+			}
+			rules = nearestWeekdayRules(base, day)
+		default:
+			monthDays, err := parseCronField(dom, 1, 31, nil)
+			if err != nil {
+				return nil, err
+			}
+			if !isFullRange(monthDays, 1, 31) {
+				base.ByMonthDay = monthDays
+			}
+		}
+	} else {
+		switch {
+		case strings.Contains(dow, "#"):
+			parts := strings.SplitN(dow, "#", 2)
+			wd, err := parseQuartzWeekday(parts[0])
+			if err != nil {
+				return nil, err
+			}
+			nth, err := strconv.Atoi(parts[1])
+			if err != nil || nth < 1 || nth > 5 {
+				return nil, BadFormatError(dow)
+			}
+			base.Frequency = MONTHLY
+			base.ByDay = []ForDay{{Weekday: wd, Offset: nth}}
+		case dow == "L":
+			base.ByDay = []ForDay{{Weekday: time.Saturday}}
+		case len(dow) > 1 && strings.HasSuffix(dow, "L"):
+			wd, err := parseQuartzWeekday(dow[:len(dow)-1])
+			if err != nil {
+				return nil, err
+			}
+			base.Frequency = MONTHLY
+			base.ByDay = []ForDay{{Weekday: wd, Offset: -1}}
+		default:
+			weekDays, err := parseCronField(dow, 1, 7, quartzDayNames)
+			if err != nil {
+				return nil, err
+			}
+			if !isFullRange(weekDays, 1, 7) {
+				for _, value := range weekDays {
+					base.ByDay = append(base.ByDay, ForDay{Weekday: time.Weekday(value - 1)})
+				}
+			}
+		}
+	}
+
+	if err := ce.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// nearestWeekdayRules expresses "nW" as the union of: day n when it is a
+// weekday, the Friday before when n is a Saturday and the Monday after
+// when n is a Sunday. Quartz never moves "1W" into the previous month, a
+// 1st on a weekend moves to the Monday after.
+func nearestWeekdayRules(base *RecurringRule, day int) RuleSet {
+	onDay := base.Clone()
+	onDay.ByMonthDay = []int16{int16(day)}
+	onDay.ByDay = append([]ForDay(nil), workWeek...)
+
+	before := base.Clone()
+	after := base.Clone()
+
+	if day == 1 {
+		before.ByMonthDay = []int16{2}
+		before.ByDay = []ForDay{{Weekday: time.Monday}}
+		after.ByMonthDay = []int16{3}
+		after.ByDay = []ForDay{{Weekday: time.Monday}}
+	} else {
+		before.ByMonthDay = []int16{int16(day - 1)}
+		before.ByDay = []ForDay{{Weekday: time.Friday}}
+		after.ByMonthDay = []int16{int16(day + 1)}
+		after.ByDay = []ForDay{{Weekday: time.Monday}}
+	}
+
+	return RuleSet{onDay, before, after}
+}
+
+// quartzYearInto limits the rule to a single year or a range of years.
+func quartzYearInto(rr *RecurringRule, field string) error {
+	if field == "" || field == "*" {
+		return nil
+	}
+
+	var first, last int
+	var err error
+
+	if strings.Contains(field, "-") {
+		parts := strings.SplitN(field, "-", 2)
+		first, err = strconv.Atoi(parts[0])
+		if err == nil {
+			last, err = strconv.Atoi(parts[1])
+		}
+	} else {
+		first, err = strconv.Atoi(field)
+		last = first
+	}
+
+	if err != nil || first > last {
+		return errors.New(fmt.Sprintf("year %q can not be represented, only a year or a range of years", field))
+	}
+
+	start := rr.DtStart
+	if start.Year() < first {
+		rr.DtStart = time.Date(first, time.January, 1,
+			start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	rr.Until = time.Date(last, time.December, 31, 23, 59, 59, 0, start.Location())
+
+	return nil
+}
+
+// untilYears returns the range of years for formats that can only bound
+// a schedule by year, UNTIL must be the last second of a year.
+func (rr *RecurringRule) untilYears(ce *ConversionError) (first, last int, ok bool) {
+	if rr.Until.Equal(EmptyTime) {
+		return 0, 0, false
+	}
+
+	until := rr.Until.In(rr.DtStart.Location())
+	if until.Month() != time.December || until.Day() != 31 ||
+		until.Hour() != 23 || until.Minute() != 59 || until.Second() != 59 {
+		ce.Add("UNTIL can only be represented at the end of a year")
+		return 0, 0, false
+	}
+
+	return rr.DtStart.Year(), until.Year(), true
+}
+
+func isLastWorkday(rr *RecurringRule) bool {
+	if rr.Frequency != MONTHLY || len(rr.BySetPos) != 1 || rr.BySetPos[0] != -1 {
+		return false
+	}
+
+	if len(rr.ByMonthDay) > 0 || len(rr.ByDay) != len(workWeek) {
+		return false
+	}
+
+	for index, fd := range rr.ByDay {
+		if fd != workWeek[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// quartzFields is shared with the EventBridge formatter, it returns the
+// second, minute, hour, day-of-month, month, day-of-week and year fields.
+// The year is empty when the rule has no UNTIL.
+func (rr *RecurringRule) quartzFields(ce *ConversionError) []string {
+	cf := rr.cronFields(ce)
+
+	var dom, dow string = "*", "?"
+
+	if isLastWorkday(rr) {
+		if len(cf.seconds)*len(cf.minutes)*len(cf.hours) != 1 {
+			ce.Add("LW with more than one time of day can not be represented")
+		}
+		dom = "LW"
+	} else {
+		if len(rr.BySetPos) > 0 {
+			ce.Add("BYSETPOS can not be represented")
+		}
+
+		if len(cf.monthDays) > 0 && len(cf.weekDays) > 0 {
+			ce.Add("BYMONTHDAY and BYDAY can not be combined")
+		}
+
+		if len(cf.monthDays) == 1 && cf.monthDays[0] < 0 {
+			if cf.monthDays[0] == -1 {
+				dom = "L"
+			} else {
+				dom = fmt.Sprintf("L-%d", -cf.monthDays[0]-1)
+			}
+		} else if len(cf.monthDays) > 0 {
+			for _, day := range cf.monthDays {
+				if day < 1 {
+					ce.Add("BYMONTHDAY=%d can only be represented alone", day)
+				}
+			}
+			dom = formatCronList(cf.monthDays, 1, 31)
+		}
+
+		// One of the day fields has to be ?, every day of the week is
+		// every day of the month.
+		if len(cf.weekDays) > 0 {
+			dom, dow = "?", rr.quartzWeekdays(cf.weekDays, ce)
+			if dow == "*" {
+				dom, dow = "*", "?"
+			}
+		}
+	}
+
+	var year string
+
+	if first, last, ok := rr.untilYears(ce); ok && first == last {
+		year = fmt.Sprintf("%d", first)
+	} else if ok {
+		year = fmt.Sprintf("%d-%d", first, last)
+	}
+
+	return []string{
+		formatCronList(cf.seconds, 0, 59),
+		formatCronList(cf.minutes, 0, 59),
+		formatCronList(cf.hours, 0, 23),
+		dom,
+		formatCronList(cf.months, 1, 12),
+		dow,
+		year,
+	}
+}
+
+func (rr *RecurringRule) quartzWeekdays(days []ForDay, ce *ConversionError) string {
+	var plain []int16
+
+	for _, fd := range days {
+		if fd.Offset == 0 {
+			plain = append(plain, int16(fd.Weekday)+1)
+			continue
+		}
+
+		if len(days) != 1 {
+			ce.Add("BYDAY=%s can only be represented alone", fd)
+			continue
+		}
+
+		if rr.Frequency != MONTHLY && !(rr.Frequency == YEARLY && len(rr.ByMonth) > 0) {
+			ce.Add("BYDAY=%s is only supported within a month", fd)
+		}
+
+		switch {
+		case fd.Offset == -1:
+			return fmt.Sprintf("%dL", int(fd.Weekday)+1)
+		case fd.Offset >= 1 && fd.Offset <= 5:
+			return fmt.Sprintf("%d#%d", int(fd.Weekday)+1, fd.Offset)
+		default:
+			ce.Add("BYDAY=%s can not be represented", fd)
+		}
+	}
+
+	return formatCronList(plain, 1, 7)
+}
+
+// QuartzString converts the rule into a Quartz cron expression, the year
+// field is only written when the rule has an UNTIL at the end of a year.
+func (rr *RecurringRule) QuartzString() (string, error) {
+	ce := &ConversionError{Format: "Quartz"}
+
+	fields := rr.quartzFields(ce)
+
+	if err := ce.Err(); err != nil {
+		return "", err
+	}
+
+	if fields[6] == "" {
+		fields = fields[:6]
+	}
+
+	return strings.Join(fields, " "), nil
+}
@@ -0,0 +1,222 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Quartz_Parse(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var cases = map[string]string{
+		"0 15 10 ? * 6#3":          "RRULE:FREQ=MONTHLY;BYSECOND=0;BYMINUTE=15;BYHOUR=10;BYDAY=3FR",
+		"0 15 10 ? * FRI#3":        "RRULE:FREQ=MONTHLY;BYSECOND=0;BYMINUTE=15;BYHOUR=10;BYDAY=3FR",
+		"0 0 12 L * ?":             "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=-1",
+		"0 0 12 L-2 * ?":           "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=-3",
+		"0 0 9 LW * ?":             "RRULE:FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"0 0 9 ? * 5L":             "RRULE:FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=9;BYDAY=-1TH",
+		"0 0/30 8-9 ? JAN MON-FRI": "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0,30;BYHOUR=8,9;BYMONTH=1;BYDAY=MO,TU,WE,TH,FR",
+		"0 0 12 1,15 * ? *":        "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=1,15",
+	}
+
+	for value, expected := range cases {
+		rules, err := ParseQuartz(value, from)
+		if err != nil || len(rules) != 1 {
+			t.Log("Failed to parse", value, err)
+			t.Fail()
+			continue
+		}
+
+		expectedRule, _ := Parse(expected)
+		expectedRule.DtStart = rules[0].DtStart
+
+		if !rules[0].Equal(expectedRule) {
+			t.Log("Rules don't match", value, rules[0].RecurString())
+			t.Fail()
+		}
+	}
+}
+
+func Test_Quartz_LastWorkday(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	rules, _ := ParseQuartz("0 0 9 LW * ?", from)
+
+	targets := []time.Time{
+		time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 29, 9, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	iter := rules.Iterator()
+
+	for _, target := range targets {
+		if !iter.Step(&event) || !event.Equal(target) {
+			t.Log("Unexpected occurrence", event, target)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Quartz_NearestWeekday(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	rules, err := ParseQuartz("0 0 9 15W * ?", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// June 15th 2024 is a Saturday and September 15th a Sunday.
+	targets := []time.Time{
+		time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.February, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.May, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.June, 14, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.July, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.August, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.September, 16, 9, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	iter := rules.Iterator().Limit(len(targets))
+
+	for _, target := range targets {
+		if !iter.Step(&event) || !event.Equal(target) {
+			t.Log("Unexpected occurrence", event, target)
+			t.Fail()
+		}
+	}
+
+	// June 1st 2024 is a Saturday, 1W moves forward to Monday the 3rd.
+	rules, _ = ParseQuartz("0 0 9 1W * ?", from)
+	iter = rules.Iterator().After(time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC))
+	if !iter.Step(&event) || !event.Equal(time.Date(2024, time.June, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Log("1W failed", event)
+		t.Fail()
+	}
+}
+
+func Test_Quartz_Year(t *testing.T) {
+	from := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	rules, err := ParseQuartz("0 0 12 1 1 ? 2024-2025", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []time.Time
+	var event time.Time
+
+	iter := rules.Iterator()
+	for iter.Step(&event) {
+		results = append(results, event)
+	}
+
+	if len(results) != 2 || results[0].Year() != 2024 || results[1].Year() != 2025 {
+		t.Log("Unexpected results", results)
+		t.Fail()
+	}
+
+	str, err := rules[0].QuartzString()
+	if err != nil || str != "0 0 12 1 1 ? 2024-2025" {
+		t.Log("Failed to format year", str, err)
+		t.Fail()
+	}
+}
+
+func Test_Quartz_Errors(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var bad = []string{
+		"0 0 12 * * *",
+		"0 0 12 ? * ?",
+		"0 0 12 30W * ?",
+		"0 0 12 ? * 6#6",
+		"0 0 12 * * ? 2024,2026",
+		"0 0 9,17 LW * ?",
+		"0 0 12 * *",
+	}
+
+	for _, value := range bad {
+		if _, err := ParseQuartz(value, from); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Quartz_ToString(t *testing.T) {
+	var cases = map[string]string{
+		"RRULE:FREQ=DAILY":                                    "0 0 9 * * ?",
+		"RRULE:FREQ=MONTHLY;BYDAY=3FR":                        "0 0 9 ? * 6#3",
+		"RRULE:FREQ=MONTHLY;BYDAY=-1TH":                       "0 0 9 ? * 5L",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1":                    "0 0 9 L * ?",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-3":                    "0 0 9 L-2 * ?",
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1": "0 0 9 LW * ?",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE":                       "0 0 9 ? * 2,4",
+		"RRULE:FREQ=WEEKLY;BYDAY=SU,MO,TU,WE,TH,FR,SA":        "0 0 9 * * ?",
+		"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH":              "0 0 9 ? 11 5#4",
+		"RRULE:FREQ=DAILY;UNTIL=19991231T235959Z":             "0 0 9 * * ? 1997-1999",
+	}
+
+	for value, expected := range cases {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		result, err := rule.QuartzString()
+		if err != nil || result != expected {
+			t.Log("Failed to convert", value, result, err)
+			t.Fail()
+		}
+	}
+
+	var unsupported = []string{
+		"RRULE:FREQ=YEARLY;BYDAY=20MO",
+		"RRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=2",
+		"RRULE:FREQ=MONTHLY;BYDAY=1MO,1FR",
+		"RRULE:FREQ=DAILY;UNTIL=19991130T000000Z",
+		"RRULE:FREQ=DAILY;COUNT=3",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		if _, err := rule.QuartzString(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Quartz_RoundTrip(t *testing.T) {
+	var cases = []string{
+		"RRULE:FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;BYDAY=SU,MO,TU,WE,TH,FR,SA",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"RRULE:FREQ=MONTHLY;BYDAY=3FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+	}
+
+	for _, value := range cases {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		expr, err := rule.QuartzString()
+		if err != nil {
+			t.Fatal(value, err)
+		}
+		rules, err := ParseQuartz(expr, rule.DtStart)
+		if err != nil {
+			t.Log("Failed to parse", expr, "from", value, err)
+			t.Fail()
+			continue
+		}
+
+		sameOccurrences(t, rule.Iterator().Limit(50), rules.Iterator().Limit(50), value, expr)
+	}
+}
@@ -0,0 +1,127 @@
+package rrule
+
+import (
+	"time"
+)
+
+// Reverse makes Step return occurrences latest first, from Before back
+// toward DtStart, or to After when it's set:
+//
+//	iter := rule.Iterator().Before(time.Now()).Reverse()
+//	iter.Step(&last)
+//
+// Without Before it starts from the end of the rule, so a rule without
+// UNTIL or COUNT has no occurrences. COUNT rules are stepped through once
+// to find their last occurrence.
+func (ri *RecurrenceIterator) Reverse() *RecurrenceIterator {
+	ri.reverse = true
+	return ri
+}
+
+func (ri *RecurrenceIterator) stepBack(t *time.Time) bool {
+	if !ri.started {
+		ri.started = true
+		if !ri.startBack() {
+			return false
+		}
+	}
+
+	for len(ri.iterBuffer) == 0 {
+		if ri.iterCounter < ri.lowest {
+			return false
+		}
+		if ri.stopped() {
+			return false
+		}
+
+		ri.iterBuffer = ri.buffer[:0]
+		times := ri.expansion.period(ri.iterCounter)
+		for i := len(times) - 1; i >= 0; i-- {
+			d := times[i]
+			if ri.UseUserAfter && !d.After(ri.UserAfter) {
+				break
+			}
+
+			if d.After(ri.last) || (ri.UseUserBefore && !d.Before(ri.UserBefore)) {
+				continue
+			}
+			if d.Before(ri.rule.DtStart) || ri.isException(d) {
+				continue
+			}
+
+			ri.iterBuffer = append(ri.iterBuffer, d)
+		}
+		ri.buffer = ri.iterBuffer
+
+		ri.iterCounter = ri.expansion.prev(ri.iterCounter)
+		ri.periods += 1
+	}
+
+	*t = ri.iterBuffer[0]
+	ri.iterBuffer = ri.iterBuffer[1:]
+
+	ri.ReturnCounter += 1
+	return true
+}
+
+// startBack finds where to start stepping back from, it's false when
+// there's no end to start from.
+func (ri *RecurrenceIterator) startBack() bool {
+	ri.last = ri.rule.Until
+	if ri.rule.Count > 0 {
+		last, ok := ri.countLast()
+		if !ok {
+			return false
+		}
+		if ri.last.Equal(EmptyTime) || last.Before(ri.last) {
+			ri.last = last
+		}
+	}
+
+	from := ri.last
+	if ri.UseUserBefore && (from.Equal(EmptyTime) || ri.UserBefore.Before(from)) {
+		from = ri.UserBefore
+	}
+	if from.Equal(EmptyTime) {
+		return false
+	}
+	if ri.last.Equal(EmptyTime) {
+		ri.last = from
+	}
+
+	// Occurrences of the period after can be before its start.
+	ri.iterCounter = ri.expansion.periodOf(from) + 1
+	if ri.UseUserAfter {
+		ri.lowest = ri.expansion.periodOf(ri.UserAfter)
+	}
+	return true
+}
+
+// countLast steps forward through a COUNT rule to its last occurrence.
+func (ri *RecurrenceIterator) countLast() (time.Time, bool) {
+	var count int
+	var last time.Time
+
+	for n := 0; count < ri.rule.Count; n = ri.expansion.next(n) {
+		if ri.stopped() {
+			return last, false
+		}
+		ri.periods += 1
+
+		for _, d := range ri.expansion.period(n) {
+			if d.Before(ri.rule.DtStart) || ri.isException(d) {
+				continue
+			}
+			if !ri.rule.Until.Equal(EmptyTime) && d.After(ri.rule.Until) {
+				return last, count > 0
+			}
+
+			last = d
+			if count += 1; count == ri.rule.Count {
+				break
+			}
+		}
+	}
+
+	return last, true
+}
@@ -0,0 +1,119 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Reverse_Conformance(t *testing.T) {
+	// Every conformance case with an end gives its occurrences backwards.
+	for _, c := range conformanceCases {
+		if !c.complete {
+			continue
+		}
+		rule, err := Parse(c.rule)
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+
+		iter := rule.Iterator().Reverse().HardLimit(100000)
+		var event time.Time
+
+		for i := len(c.expected) - 1; i >= 0; i-- {
+			expected, _ := ParseDateTime(c.expected[i], rule.DtStart.Location())
+			if !iter.Step(&event) {
+				t.Log(c.name, "ended before", c.expected[i])
+				t.Fail()
+				break
+			}
+			if !event.Equal(expected) {
+				t.Log(c.name, "expected", expected, "got", event)
+				t.Fail()
+				break
+			}
+		}
+
+		if iter.Step(&event) {
+			t.Log(c.name, "has more occurrences", event)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Reverse_Before(t *testing.T) {
+	// The last working day of the month, before payday in November.
+	rule, _ := Parse(
+		"DTSTART;TZID=Europe/London:20240131T170000\n" +
+			"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1\n" +
+			"EXDATE;TZID=Europe/London:20240830T170000",
+	)
+
+	iter := rule.Iterator().Before(time.Date(2024, time.November, 29, 17, 0, 0, 0, time.UTC)).Reverse()
+	expected := []time.Time{
+		time.Date(2024, time.October, 31, 17, 0, 0, 0, time.UTC),
+		time.Date(2024, time.September, 30, 16, 0, 0, 0, time.UTC),
+		time.Date(2024, time.July, 31, 16, 0, 0, 0, time.UTC),
+	}
+
+	var event time.Time
+	for _, value := range expected {
+		if !iter.Step(&event) || !event.Equal(value) {
+			t.Log("expected", value, "got", event)
+			t.Fail()
+		}
+	}
+
+	// After stops it.
+	iter = rule.Iterator().Between(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)).Reverse()
+	var count int
+	for iter.Step(&event) {
+		count += 1
+	}
+	if count != 2 {
+		t.Log("expected 2 occurrences between May and July, got", count)
+		t.Fail()
+	}
+}
+
+func Test_Reverse_Count(t *testing.T) {
+	// COUNT is counted from DTSTART, the ones after Before still count.
+	rule, _ := Parse(
+		"DTSTART;TZID=America/New_York:20240101T090000\n" +
+			"RRULE:FREQ=DAILY;COUNT=10\n" +
+			"EXDATE;TZID=America/New_York:20240103T090000",
+	)
+
+	iter := rule.Iterator().Reverse()
+	var event time.Time
+	if !iter.Step(&event) || !event.Equal(time.Date(2024, time.January, 11, 9, 0, 0, 0, rule.DtStart.Location())) {
+		t.Log("expected the tenth occurrence on the 11th, got", event)
+		t.Fail()
+	}
+
+	iter = rule.Iterator().Before(time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC)).Reverse()
+	var count int
+	for iter.Step(&event) {
+		count += 1
+	}
+	if count != 2 {
+		t.Log("expected 2 occurrences before the 4th, got", count)
+		t.Fail()
+	}
+}
+
+func Test_Reverse_Unbounded(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20150101T000000\nRRULE:FREQ=MINUTELY;BYHOUR=9;BYMINUTE=30")
+
+	var event time.Time
+	if rule.Iterator().Reverse().Step(&event) {
+		t.Log("a rule without an end has no last occurrence", event)
+		t.Fail()
+	}
+
+	// Days and hours without occurrences are skipped whole.
+	iter := rule.Iterator().Before(time.Date(2026, time.October, 19, 9, 30, 0, 0, time.UTC)).Reverse().HardLimit(10)
+	if !iter.Step(&event) || !event.Equal(time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC)) {
+		t.Log("expected yesterday morning, got", event, iter.IsHardLimitReached())
+		t.Fail()
+	}
+}
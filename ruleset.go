@@ -0,0 +1,104 @@
+package rrule
+
+import (
+	"time"
+)
+
+// RuleSet is the union of the occurrences of several rules, some formats
+// (cron for example) can only be expressed as more than one RRULE.
+type RuleSet []*RecurringRule
+
+// RuleSetIterator merges the iterators of every rule in a RuleSet,
+// occurrences are returned in order and duplicates are only returned once.
+type RuleSetIterator struct {
+	iters   []*RecurrenceIterator
+	heads   []time.Time
+	hasHead []bool
+	primed  bool
+
+	ReturnCounter int
+
+	UserLimit int
+}
+
+func (rs RuleSet) Iterator() *RuleSetIterator {
+	rsi := &RuleSetIterator{}
+
+	for _, rule := range rs {
+		rsi.iters = append(rsi.iters, rule.Iterator())
+	}
+
+	rsi.heads = make([]time.Time, len(rsi.iters))
+	rsi.hasHead = make([]bool, len(rsi.iters))
+
+	return rsi
+}
+
+func (rsi *RuleSetIterator) Limit(i int) *RuleSetIterator {
+	rsi.UserLimit = i
+	return rsi
+}
+
+func (rsi *RuleSetIterator) HardLimit(limit int) *RuleSetIterator {
+	for _, iter := range rsi.iters {
+		iter.HardLimit(limit)
+	}
+	return rsi
+}
+
+func (rsi *RuleSetIterator) Before(b time.Time) *RuleSetIterator {
+	for _, iter := range rsi.iters {
+		iter.Before(b)
+	}
+	return rsi
+}
+
+func (rsi *RuleSetIterator) After(a time.Time) *RuleSetIterator {
+	for _, iter := range rsi.iters {
+		iter.After(a)
+	}
+	return rsi
+}
+
+func (rsi *RuleSetIterator) Between(a, b time.Time) *RuleSetIterator {
+	rsi.After(a)
+	rsi.Before(b)
+	return rsi
+}
+
+func (rsi *RuleSetIterator) Step(t *time.Time) bool {
+	if rsi.UserLimit > 0 && rsi.ReturnCounter >= rsi.UserLimit {
+		return false
+	}
+
+	if !rsi.primed {
+		for index, iter := range rsi.iters {
+			rsi.hasHead[index] = iter.Step(&rsi.heads[index])
+		}
+		rsi.primed = true
+	}
+
+	var next int = -1
+
+	for index := range rsi.iters {
+		if rsi.hasHead[index] && (next < 0 || rsi.heads[index].Before(rsi.heads[next])) {
+			next = index
+		}
+	}
+
+	if next < 0 {
+		return false
+	}
+
+	*t = rsi.heads[next]
+
+	for index, iter := range rsi.iters {
+		for rsi.hasHead[index] && rsi.heads[index].Equal(*t) {
+			rsi.hasHead[index] = iter.Step(&rsi.heads[index])
+		}
+	}
+
+	rsi.ReturnCounter += 1
+
+	return true
+}
@@ -0,0 +1,81 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_After_Conformance(t *testing.T) {
+	// Starting halfway through every conformance case gives the rest of it.
+	for _, c := range conformanceCases {
+		if len(c.expected) < 2 {
+			continue
+		}
+		rule, err := Parse(c.rule)
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+
+		half := len(c.expected) / 2
+		after, _ := ParseDateTime(c.expected[half-1], rule.DtStart.Location())
+		iter := rule.Iterator().After(after).HardLimit(100000)
+		var event time.Time
+
+		for _, value := range c.expected[half:] {
+			expected, _ := ParseDateTime(value, rule.DtStart.Location())
+			if !iter.Step(&event) {
+				t.Log(c.name, "ended before", value)
+				t.Fail()
+				break
+			}
+			if !event.Equal(expected) {
+				t.Log(c.name, "expected", expected, "got", event)
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func Test_After_Seeks(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=Europe/Berlin:20150101T000000\nRRULE:FREQ=MINUTELY;INTERVAL=7")
+
+	// Stepping through every seven minutes since 2015 would take
+	// more than a million periods.
+	iter := rule.Iterator().After(time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)).HardLimit(5)
+
+	var event time.Time
+	if !iter.Step(&event) {
+		t.Fatal("no occurrence after seeking", iter.IsHardLimitReached())
+	}
+
+	expected := time.Date(2026, time.October, 18, 12, 3, 0, 0, time.UTC)
+	if !event.Equal(expected) {
+		t.Log("expected", expected, "got", event)
+		t.Fail()
+	}
+}
+
+func Test_After_SkipsCount(t *testing.T) {
+	// A COUNT rule is stepped from DTSTART, but days, hours and minutes
+	// without occurrences are skipped whole.
+	rule, _ := Parse(
+		"DTSTART;TZID=UTC:20150101T000000\n" +
+			"RRULE:FREQ=SECONDLY;COUNT=3;BYMONTH=2;BYMONTHDAY=29;BYHOUR=12;BYMINUTE=0;BYSECOND=0",
+	)
+
+	iter := rule.Iterator().After(time.Date(2015, time.June, 1, 0, 0, 0, 0, time.UTC)).HardLimit(10000)
+	var event time.Time
+
+	for _, year := range []int{2016, 2020, 2024} {
+		if !iter.Step(&event) {
+			t.Fatal("ended before", year, iter.IsHardLimitReached())
+		}
+
+		expected := time.Date(year, time.February, 29, 12, 0, 0, 0, time.UTC)
+		if !event.Equal(expected) {
+			t.Log("expected", expected, "got", event)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package rrule
+
+import (
+	"iter"
+	"time"
+)
+
+// All returns the occurrences of the rule for a range loop, which has to
+// break out of it when the rule has no end:
+//
+//	for t := range rule.All() {
+//		if t.After(end) {
+//			break
+//		}
+//	}
+//
+// Each loop steps its own RecurrenceIterator, so the rule is read when the
+// loop starts.
+func (rr *RecurringRule) All() iter.Seq[time.Time] {
+	return values(rr.AllIndexed())
+}
+
+// Between returns the occurrences after a and before b, as
+// RecurrenceIterator.Between.
+func (rr *RecurringRule) Between(a, b time.Time) iter.Seq[time.Time] {
+	return values(rr.BetweenIndexed(a, b))
+}
+
+// After returns the occurrences after t, as RecurrenceIterator.After.
+func (rr *RecurringRule) After(t time.Time) iter.Seq[time.Time] {
+	return values(rr.AfterIndexed(t))
+}
+
+// AllIndexed is All with the index of each occurrence, from 0.
+func (rr *RecurringRule) AllIndexed() iter.Seq2[int, time.Time] {
+	return indexed(func() *RecurrenceIterator {
+		return rr.Iterator()
+	})
+}
+
+// BetweenIndexed is Between with the index of each occurrence in the
+// window, from 0.
+func (rr *RecurringRule) BetweenIndexed(a, b time.Time) iter.Seq2[int, time.Time] {
+	return indexed(func() *RecurrenceIterator {
+		return rr.Iterator().Between(a, b)
+	})
+}
+
+// AfterIndexed is After with the index of each occurrence after t, from 0.
+func (rr *RecurringRule) AfterIndexed(t time.Time) iter.Seq2[int, time.Time] {
+	return indexed(func() *RecurrenceIterator {
+		return rr.Iterator().After(t)
+	})
+}
+
+func indexed(start func() *RecurrenceIterator) iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		ri := start()
+		var t time.Time
+		for i := 0; ri.Step(&t); i++ {
+			if !yield(i, t) {
+				return
+			}
+		}
+	}
+}
+
+func values(seq iter.Seq2[int, time.Time]) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		for _, t := range seq {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Seq_All(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=10")
+
+	var count int
+	for event := range rule.All() {
+		expected := rule.DtStart.AddDate(0, 0, count)
+		if !event.Equal(expected) {
+			t.Log("expected", expected, "got", event)
+			t.Fail()
+		}
+		count += 1
+	}
+	if count != 10 {
+		t.Log("expected 10 occurrences, got", count)
+		t.Fail()
+	}
+
+	// Each loop starts again from DTSTART.
+	for event := range rule.All() {
+		if !event.Equal(rule.DtStart) {
+			t.Log("second loop started at", event)
+			t.Fail()
+		}
+		break
+	}
+}
+
+func Test_Seq_Break(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR")
+
+	var count int
+	for range rule.All() {
+		if count += 1; count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Log("break didn't stop the loop", count)
+		t.Fail()
+	}
+}
+
+func Test_Seq_Windows(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=DAILY")
+	a := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC)
+
+	var count int
+	for event := range rule.Between(a, b) {
+		if !event.After(a) || !event.Before(b) {
+			t.Log("outside the window", event)
+			t.Fail()
+		}
+		count += 1
+	}
+	if count != 7 {
+		t.Log("expected 7 occurrences in a week, got", count)
+		t.Fail()
+	}
+
+	for i, event := range rule.AfterIndexed(a) {
+		expected := time.Date(2024, time.March, 1+i, 9, 0, 0, 0, time.UTC)
+		if !event.Equal(expected) {
+			t.Log(i, "expected", expected, "got", event)
+			t.Fail()
+		}
+		if i == 4 {
+			break
+		}
+	}
+}
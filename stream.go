@@ -0,0 +1,91 @@
+package rrule
+
+import (
+	"context"
+	"time"
+)
+
+// Window limits an expansion to the occurrences after After and before
+// Before. A zero time leaves that side open.
+type Window struct {
+	After  time.Time
+	Before time.Time
+}
+
+func (w Window) iterator(rr *RecurringRule) *RecurrenceIterator {
+	ri := rr.Iterator()
+	if !w.After.IsZero() {
+		ri.After(w.After)
+	}
+	if !w.Before.IsZero() {
+		ri.Before(w.Before)
+	}
+	return ri
+}
+
+// ExpandContext returns the occurrences of the rule in the window. When ctx
+// is done first, it returns the ones found so far and the context's error.
+func ExpandContext(ctx context.Context, rr *RecurringRule, window Window) ([]time.Time, error) {
+	ri := window.iterator(rr).Context(ctx)
+
+	var results []time.Time
+	var t time.Time
+	for ri.Step(&t) {
+		results = append(results, t)
+	}
+	return results, ri.Err()
+}
+
+// Stream sends the occurrences of a rule on C as they're found, for
+// expansions too long to hold or wait for:
+//
+//	stream := rrule.NewStream(r.Context(), rule, window, 64)
+//	for t := range stream.C {
+//		...
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+//
+// C is closed at the end of the rule or the window, or when the context is
+// done. A receiver that stops early has to cancel the context so the
+// producer isn't left waiting to send.
+type Stream struct {
+	C <-chan time.Time
+
+	err error
+}
+
+// NewStream starts expanding the rule in the window, with up to buffer
+// occurrences waiting on C to be received.
+func NewStream(ctx context.Context, rr *RecurringRule, window Window, buffer int) *Stream {
+	c := make(chan time.Time, buffer)
+	stream := &Stream{C: c}
+
+	// The iterator is made here so later changes to rr don't race with
+	// the producer.
+	ri := window.iterator(rr).Context(ctx)
+
+	go func() {
+		defer close(c)
+
+		var t time.Time
+		for ri.Step(&t) {
+			select {
+			case c <- t:
+			case <-ctx.Done():
+				stream.err = ctx.Err()
+				return
+			}
+		}
+		stream.err = ri.Err()
+	}()
+
+	return stream
+}
+
+// Err is why the stream ended early, after C is closed. It's nil when every
+// occurrence was sent.
+func (s *Stream) Err() error {
+	return s.err
+}
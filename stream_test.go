@@ -0,0 +1,85 @@
+package rrule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_ExpandContext(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=DAILY")
+	window := Window{
+		After:  time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Before: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	results, err := ExpandContext(context.Background(), rule, window)
+	if err != nil || len(results) != 31 {
+		t.Log("expected the 31 days of March", len(results), err)
+		t.Fail()
+	}
+}
+
+func Test_ExpandContext_Cancelled(t *testing.T) {
+	// There's no February 30th, so this never finds anything or ends.
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=DAILY;BYMONTH=2;BYMONTHDAY=30")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results, err := ExpandContext(ctx, rule, Window{})
+	if !errors.Is(err, context.DeadlineExceeded) || len(results) != 0 {
+		t.Log("expected the deadline to stop it", results, err)
+		t.Fail()
+	}
+	if time.Since(start) > time.Second {
+		t.Log("took too long to stop", time.Since(start))
+		t.Fail()
+	}
+}
+
+func Test_Stream(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=10")
+
+	stream := NewStream(context.Background(), rule, Window{}, 2)
+	var count int
+	for event := range stream.C {
+		expected := rule.DtStart.AddDate(0, 0, count)
+		if !event.Equal(expected) {
+			t.Log("expected", expected, "got", event)
+			t.Fail()
+		}
+		count += 1
+	}
+
+	if count != 10 || stream.Err() != nil {
+		t.Log("expected 10 occurrences", count, stream.Err())
+		t.Fail()
+	}
+}
+
+func Test_Stream_Cancel(t *testing.T) {
+	rule, _ := Parse("DTSTART;TZID=UTC:20240101T090000\nRRULE:FREQ=MINUTELY")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := NewStream(ctx, rule, Window{}, 4)
+
+	var count int
+	for range stream.C {
+		if count += 1; count == 3 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(stream.Err(), context.Canceled) {
+		t.Log("expected the stream to be cancelled", stream.Err())
+		t.Fail()
+	}
+	if count > 3+4+1 {
+		t.Log("kept sending after being cancelled", count)
+		t.Fail()
+	}
+}
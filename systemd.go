@@ -0,0 +1,347 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemd timers describe calendar events (OnCalendar=, see
+// systemd.time(7)) as
+//
+//	[weekdays] [year-month-day] [hour:minute[:second]] [timezone]
+//
+// Every component accepts lists, ranges (a..b) and repetitions (a/n),
+// "~" in the date counts days from the end of the month. Unlike cron the
+// weekdays and the date must both match, so a single rule is enough.
+
+var systemdShortcuts = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+var systemdDayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	"SUNDAY": 0, "MONDAY": 1, "TUESDAY": 2, "WEDNESDAY": 3,
+	"THURSDAY": 4, "FRIDAY": 5, "SATURDAY": 6,
+}
+
+func parseSystemdField(field string, min, max int, names map[string]int) ([]int16, error) {
+	return parseCronField(strings.ReplaceAll(field, "..", "-"), min, max, names)
+}
+
+// parseSystemdFromEnd expands the days after "~", counted back from the
+// last day of the month. Repetitions run towards the last day, "~07/1" is
+// the last seven days, so the field is mirrored to count forwards like any
+// other and the days are mirrored back.
+func parseSystemdFromEnd(field string) ([]int16, error) {
+	var items []string
+	for _, item := range strings.Split(field, ",") {
+		value, step, stepped := strings.Cut(item, "/")
+
+		bounds := strings.Split(value, "..")
+		for i, j := 0, len(bounds)-1; i < j; i, j = i+1, j-1 {
+			bounds[i], bounds[j] = bounds[j], bounds[i]
+		}
+		for i, bound := range bounds {
+			if day, err := strconv.Atoi(bound); err == nil {
+				bounds[i] = strconv.Itoa(32 - day)
+			}
+		}
+
+		item = strings.Join(bounds, "..")
+		if stepped {
+			item += "/" + step
+		}
+		items = append(items, item)
+	}
+
+	days, err := parseSystemdField(strings.Join(items, ","), 1, 31, nil)
+	if err != nil {
+		return nil, BadFormatError(field)
+	}
+	for i := range days {
+		days[i] = 32 - days[i]
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+
+	return days, nil
+}
+
+func isSystemdWeekdays(s string) bool {
+	for _, item := range strings.Split(strings.ReplaceAll(s, "..", ","), ",") {
+		if _, ok := systemdDayNames[strings.ToUpper(item)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSystemdCalendar converts a systemd OnCalendar= expression into a
+// RecurringRule starting on the day of from. A trailing time zone replaces
+// the location of from.
+func ParseSystemdCalendar(expr string, from time.Time) (*RecurringRule, error) {
+	ce := &ConversionError{Format: "systemd"}
+
+	expr = strings.TrimSpace(expr)
+	if shortcut, ok := systemdShortcuts[strings.ToLower(expr)]; ok {
+		expr = shortcut
+	}
+
+	var weekdays, date, clock string = "", "*-*-*", "00:00:00"
+	var loc = from.Location()
+
+	tokens := strings.Fields(expr)
+	for index, token := range tokens {
+		if index > 0 && index == len(tokens)-1 {
+			if tz, err := time.LoadLocation(token); err == nil {
+				loc = tz
+				continue
+			}
+		}
+
+		switch {
+		case strings.Contains(token, ":"):
+			clock = token
+		case strings.Contains(token, "-") || strings.Contains(token, "~"):
+			date = token
+		case index == 0 && isSystemdWeekdays(token):
+			weekdays = token
+		default:
+			return nil, BadFormatError(token)
+		}
+	}
+
+	from = from.In(loc)
+
+	rr := &RecurringRule{
+		DtStart:       time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc),
+		Frequency:     DAILY,
+		Interval:      1,
+		WorkWeekStart: time.Monday,
+	}
+
+	clockParts := strings.Split(clock, ":")
+	if len(clockParts) == 2 {
+		clockParts = append(clockParts, "00")
+	}
+	if len(clockParts) != 3 {
+		return nil, BadFormatError(clock)
+	}
+	if strings.Contains(clockParts[2], ".") {
+		ce.Add("fractional seconds %q can not be represented", clockParts[2])
+		return nil, ce
+	}
+
+	var err error
+	if rr.ByHour, err = parseSystemdField(clockParts[0], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if rr.ByMinute, err = parseSystemdField(clockParts[1], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if rr.BySecond, err = parseSystemdField(clockParts[2], 0, 59, nil); err != nil {
+		return nil, err
+	}
+
+	var year, month, day string
+	var fromEnd bool
+
+	if strings.Contains(date, "~") {
+		parts := strings.SplitN(date, "~", 2)
+		day = parts[1]
+		fromEnd = true
+		date = parts[0]
+		if ym := strings.SplitN(date, "-", 2); len(ym) == 2 {
+			year, month = ym[0], ym[1]
+		} else {
+			year, month = "*", ym[0]
+		}
+	} else {
+		parts := strings.Split(date, "-")
+		switch len(parts) {
+		case 3:
+			year, month, day = parts[0], parts[1], parts[2]
+		case 2:
+			year, month, day = "*", parts[0], parts[1]
+		default:
+			return nil, BadFormatError(date)
+		}
+	}
+
+	months, err := parseSystemdField(month, 1, 12, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !isFullRange(months, 1, 12) {
+		rr.ByMonth = months
+	}
+
+	if fromEnd {
+		monthDays, err := parseSystemdFromEnd(day)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range monthDays {
+			rr.ByMonthDay = append(rr.ByMonthDay, -d)
+		}
+	} else {
+		monthDays, err := parseSystemdField(day, 1, 31, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !isFullRange(monthDays, 1, 31) {
+			rr.ByMonthDay = monthDays
+		}
+	}
+
+	if err := quartzYearInto(rr, strings.ReplaceAll(year, "..", "-")); err != nil {
+		ce.Add("%s", err)
+	}
+
+	if weekdays != "" {
+		values, err := parseSystemdField(weekdays, 0, 6, systemdDayNames)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 7 {
+			rr.ByDay = cronWeekdays(values)
+		}
+	}
+
+	if err := ce.Err(); err != nil {
+		return nil, err
+	}
+
+	return rr, nil
+}
+
+// formatSystemdList writes zero padded values, ranges of three or more use
+// "a..b" and "*" is used when every value is present.
+func formatSystemdList(values []int16, min, max int, width int) string {
+	list := formatCronList(values, min, max)
+	if list == "*" {
+		return list
+	}
+
+	var parts []string
+	for _, part := range strings.Split(list, ",") {
+		var bounds []string
+		for _, bound := range strings.Split(part, "-") {
+			var value int
+			fmt.Sscanf(bound, "%d", &value)
+			bounds = append(bounds, fmt.Sprintf("%0*d", width, value))
+		}
+		parts = append(parts, strings.Join(bounds, ".."))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func formatSystemdWeekdays(days []ForDay) string {
+	names := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+	// Ranges are written Monday first, Mon..Sun is a valid range.
+	var order []int
+	for _, fd := range days {
+		order = append(order, (int(fd.Weekday)+6)%7)
+	}
+	sort.Ints(order)
+
+	var parts []string
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && order[j+1] == order[j]+1 {
+			j += 1
+		}
+
+		if j-i >= 2 {
+			parts = append(parts, names[(order[i]+1)%7]+".."+names[(order[j]+1)%7])
+		} else {
+			for k := i; k <= j; k += 1 {
+				parts = append(parts, names[(order[k]+1)%7])
+			}
+		}
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// SystemdOnCalendar converts the rule into a systemd OnCalendar= expression.
+func (rr *RecurringRule) SystemdOnCalendar() (string, error) {
+	ce := &ConversionError{Format: "systemd"}
+
+	if len(rr.BySetPos) > 0 {
+		ce.Add("BYSETPOS can not be represented")
+	}
+
+	cf := rr.cronFields(ce)
+
+	var parts []string
+
+	if len(cf.weekDays) > 0 {
+		for _, fd := range cf.weekDays {
+			if fd.Offset != 0 {
+				ce.Add("BYDAY=%s can not be represented", fd)
+			}
+		}
+		if len(cf.weekDays) < 7 {
+			parts = append(parts, formatSystemdWeekdays(cf.weekDays))
+		}
+	}
+
+	var year string = "*"
+
+	if first, last, ok := rr.untilYears(ce); ok && first == last {
+		year = fmt.Sprintf("%04d", first)
+	} else if ok {
+		year = fmt.Sprintf("%04d..%04d", first, last)
+	}
+
+	var negative, positive []int16
+	for _, day := range cf.monthDays {
+		if day < 0 {
+			negative = append(negative, -day)
+		} else {
+			positive = append(positive, day)
+		}
+	}
+
+	months := formatSystemdList(cf.months, 1, 12, 2)
+
+	switch {
+	case len(negative) > 0 && len(positive) > 0:
+		ce.Add("BYMONTHDAY can't mix positive and negative days")
+	case len(negative) > 0:
+		parts = append(parts, fmt.Sprintf("%s-%s~%s", year, months, formatSystemdList(negative, 1, 31, 2)))
+	default:
+		parts = append(parts, fmt.Sprintf("%s-%s-%s", year, months, formatSystemdList(positive, 1, 31, 2)))
+	}
+
+	parts = append(parts, fmt.Sprintf(
+		"%s:%s:%s",
+		formatSystemdList(cf.hours, 0, 23, 2),
+		formatSystemdList(cf.minutes, 0, 59, 2),
+		formatSystemdList(cf.seconds, 0, 59, 2),
+	))
+
+	if loc := rr.DtStart.Location(); loc != time.Local {
+		parts = append(parts, loc.String())
+	}
+
+	if err := ce.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.Join(parts, " "), nil
+}
@@ -0,0 +1,171 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Systemd_Parse(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var cases = map[string]string{
+		"daily":                   "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=0",
+		"weekly":                  "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYDAY=MO",
+		"quarterly":               "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYMONTH=1,4,7,10;BYMONTHDAY=1",
+		"Mon..Fri *-*-* 09:30":    "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=30;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR",
+		"*-*~01 18:00:00":         "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=18;BYMONTHDAY=-1",
+		"*-02~03":                 "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYMONTH=2;BYMONTHDAY=-3",
+		"*-*-1,15 *:0/15:00":      "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0,15,30,45;BYHOUR=0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23;BYMONTHDAY=1,15",
+		"Sat,Sun 12-25 10:00 UTC": "RRULE:FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=10;BYMONTH=12;BYMONTHDAY=25;BYDAY=SU,SA",
+	}
+
+	for value, expected := range cases {
+		rule, err := ParseSystemdCalendar(value, from)
+		if err != nil {
+			t.Log("Failed to parse", value, err)
+			t.Fail()
+			continue
+		}
+
+		expectedRule, _ := Parse(expected)
+		expectedRule.DtStart = from
+
+		if !rule.Equal(expectedRule) {
+			t.Log("Rules don't match", value, rule.RecurString())
+			t.Fail()
+		}
+	}
+
+	rule, err := ParseSystemdCalendar("*-*-* 09:00:00 America/New_York", from)
+	if err != nil || rule.DtStart.Location().String() != "America/New_York" {
+		t.Log("Failed to parse the time zone", rule, err)
+		t.Fail()
+	}
+
+	rule, err = ParseSystemdCalendar("2024..2025-01-01 00:00:00", from)
+	if err != nil || rule.Until.Year() != 2025 {
+		t.Log("Failed to parse the year range", rule, err)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"Funday 10:00", "*-*-* 10:00:00.5", "*-13-01", "2024,2026-01-01"} {
+		if _, err := ParseSystemdCalendar(bad, from); err == nil {
+			t.Log("Expected error for", bad)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Systemd_FromEnd(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Repetitions after "~" run towards the end of the month.
+	var cases = map[string][]time.Time{
+		"Mon *-05~07/1 09:00": {
+			time.Date(2024, time.May, 27, 9, 0, 0, 0, time.UTC),
+			time.Date(2025, time.May, 26, 9, 0, 0, 0, time.UTC),
+			time.Date(2026, time.May, 25, 9, 0, 0, 0, time.UTC),
+			time.Date(2027, time.May, 31, 9, 0, 0, 0, time.UTC),
+		},
+		"*-02~03/2 12:00": {
+			time.Date(2024, time.February, 27, 12, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC),
+			time.Date(2025, time.February, 26, 12, 0, 0, 0, time.UTC),
+			time.Date(2025, time.February, 28, 12, 0, 0, 0, time.UTC),
+		},
+		"*-*~02..03 00:00": {
+			time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, time.January, 30, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 27, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for value, expected := range cases {
+		rule, err := ParseSystemdCalendar(value, from)
+		if err != nil {
+			t.Log("Failed to parse", value, err)
+			t.Fail()
+			continue
+		}
+
+		var event time.Time
+		iter := rule.Iterator().Limit(len(expected))
+		for _, target := range expected {
+			if !iter.Step(&event) || !event.Equal(target) {
+				t.Log("Unexpected occurrence", value, event, target)
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func Test_Systemd_ToString(t *testing.T) {
+	var cases = map[string]string{
+		"RRULE:FREQ=DAILY":                                         "*-*-* 09:00:00 UTC",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR":                   "Mon..Fri *-*-* 09:00:00 UTC",
+		"RRULE:FREQ=WEEKLY;BYDAY=SA,SU":                            "Sat,Sun *-*-* 09:00:00 UTC",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1":                         "*-*~01 09:00:00 UTC",
+		"RRULE:FREQ=YEARLY":                                        "*-09-02 09:00:00 UTC",
+		"RRULE:FREQ=MINUTELY;INTERVAL=15":                          "*-*-* *:00,15,30,45:00 UTC",
+		"RRULE:FREQ=DAILY;BYHOUR=8,9,10,11;UNTIL=19981231T235959Z": "1997..1998-*-* 08..11:00:00 UTC",
+	}
+
+	for value, expected := range cases {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		result, err := rule.SystemdOnCalendar()
+		if err != nil || result != expected {
+			t.Log("Failed to convert", value, result, err)
+			t.Fail()
+		}
+	}
+
+	var unsupported = []string{
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2",
+		"RRULE:FREQ=DAILY;COUNT=2",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		if _, err := rule.SystemdOnCalendar(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Systemd_RoundTrip(t *testing.T) {
+	var cases = []string{
+		"RRULE:FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+		"RRULE:FREQ=YEARLY",
+		"RRULE:FREQ=MINUTELY;INTERVAL=15",
+		"RRULE:FREQ=DAILY;BYHOUR=8,9,10,11;UNTIL=19981231T235959Z",
+	}
+
+	for _, value := range cases {
+		rule, _ := Parse(value)
+		rule.DtStart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+
+		calendar, err := rule.SystemdOnCalendar()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseSystemdCalendar(calendar, rule.DtStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Both have the same occurrences, the calendar starts at midnight.
+		iterB := parsed.Iterator().After(rule.DtStart.Add(-time.Second)).Limit(100)
+		sameOccurrences(t, rule.Iterator().Limit(100), iterB, value, calendar)
+	}
+}
@@ -3,8 +3,32 @@ package rrule
 import (
 	"log"
 	"testing"
+	"time"
 )
 
+// stepper is any of the iterators, to compare them with each other.
+type stepper interface {
+	Step(t *time.Time) bool
+}
+
+// sameOccurrences fails the test when a and b don't step through the same
+// occurrences, including when b has occurrences left after a. The details
+// are logged with each problem.
+func sameOccurrences(t *testing.T, a, b stepper, details ...interface{}) {
+	var x, y time.Time
+	for a.Step(&x) {
+		if !b.Step(&y) || !x.Equal(y) {
+			t.Log(append([]interface{}{"Occurrences don't match"}, append(details, x, y)...)...)
+			t.Fail()
+			return
+		}
+	}
+	if b.Step(&y) {
+		t.Log(append([]interface{}{"Unexpected occurrence"}, append(details, y)...)...)
+		t.Fail()
+	}
+}
+
 func Test_leap_year_negative(t *testing.T) {
 	var not_leap_years []int = []int{1700, 1800, 1900, 2100, 2200, 2300, 2500, 2600}
 	for _, year := range not_leap_years {
@@ -0,0 +1,382 @@
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vCalendar 1.0 (the predecessor of iCalendar) uses a compact recurrence
+// grammar, for example "W2 MO TU #10" or "MD1 1 15 #0". Each rule is a
+// frequency token with an interval, an optional list of modifiers and a
+// duration, which is either #count (#0 is forever) or an end date.
+//
+//	D<interval> [<time>...]
+//	W<interval> [<weekday>...]
+//	MP<interval> [<occurrence> <weekday>...]
+//	MD<interval> [<daynumber>...]
+//	YM<interval> [<month>...]
+//	YD<interval> [<yearday>...]
+
+// vCalDefaultCount is used when a rule has no duration.
+const vCalDefaultCount = 2
+
+// ParseVCalendar converts a vCalendar 1.0 recurrence rule into a
+// RecurringRule starting at dtStart. Nested rules (more than one frequency
+// token) are not supported.
+func ParseVCalendar(rule string, dtStart time.Time) (*RecurringRule, error) {
+	ce := &ConversionError{Format: "vCalendar"}
+
+	rr := &RecurringRule{
+		DtStart:       dtStart,
+		Interval:      1,
+		WorkWeekStart: time.Monday,
+		Count:         vCalDefaultCount,
+	}
+
+	tokens := strings.Fields(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"))
+	if len(tokens) == 0 {
+		ce.Add("empty rule")
+		return rr, ce
+	}
+
+	var kind string
+	var intervalString string
+
+	for _, prefix := range []string{"MP", "MD", "YM", "YD", "D", "W"} {
+		if strings.HasPrefix(tokens[0], prefix) {
+			kind = prefix
+			intervalString = tokens[0][len(prefix):]
+			break
+		}
+	}
+
+	switch kind {
+	case "D":
+		rr.Frequency = DAILY
+	case "W":
+		rr.Frequency = WEEKLY
+	case "MP", "MD":
+		rr.Frequency = MONTHLY
+	case "YM", "YD":
+		rr.Frequency = YEARLY
+	default:
+		ce.Add("unknown frequency %q", tokens[0])
+		return rr, ce
+	}
+
+	interval, err := strconv.Atoi(intervalString)
+	if err != nil || interval < 1 {
+		ce.Add("invalid interval %q", tokens[0])
+	} else {
+		rr.Interval = interval
+	}
+
+	var hours, minutes []int16
+	var times = map[[2]int16]bool{}
+	var pendingOccurrences []int
+	var lastWasWeekday bool
+
+	for _, token := range tokens[1:] {
+		if strings.HasPrefix(token, "#") {
+			count, err := strconv.Atoi(token[1:])
+			if err != nil || count < 0 {
+				ce.Add("invalid duration %q", token)
+				continue
+			}
+			rr.Count = count
+			continue
+		}
+
+		if len(token) >= 8 && isDigits(token[0:8]) {
+			until, err := ParseDateTime(token, dtStart.Location())
+			if err != nil {
+				ce.Add("invalid end date %q", token)
+				continue
+			}
+			rr.Until = until
+			rr.Count = 0
+			continue
+		}
+
+		if strings.HasSuffix(token, "$") {
+			ce.Add("%q: the $ modifier is not supported", token)
+			continue
+		}
+
+		if isVCalFrequency(token) {
+			ce.Add("nested rule %q is not supported", token)
+			continue
+		}
+
+		switch kind {
+		case "D":
+			if len(token) != 4 || !isDigits(token) {
+				ce.Add("invalid time %q", token)
+				continue
+			}
+			h, _ := strconv.Atoi(token[0:2])
+			m, _ := strconv.Atoi(token[2:4])
+			hours = appendUniqueInt16(hours, int16(h))
+			minutes = appendUniqueInt16(minutes, int16(m))
+			times[[2]int16{int16(h), int16(m)}] = true
+		case "W":
+			if !isVCalWeekday(token) {
+				ce.Add("invalid weekday %q", token)
+				continue
+			}
+			rr.ByDay = append(rr.ByDay, ForDay{Weekday: weekdayFromShort(token)})
+		case "MP":
+			if isVCalWeekday(token) {
+				if len(pendingOccurrences) == 0 {
+					ce.Add("weekday %q has no occurrence", token)
+				}
+				for _, offset := range pendingOccurrences {
+					rr.ByDay = append(rr.ByDay, ForDay{Weekday: weekdayFromShort(token), Offset: offset})
+				}
+				lastWasWeekday = true
+				continue
+			}
+
+			offset, ok := parseVCalNumber(token)
+			if !ok || offset < -5 || offset > 5 {
+				ce.Add("invalid occurrence %q", token)
+				continue
+			}
+			if lastWasWeekday {
+				pendingOccurrences = nil
+				lastWasWeekday = false
+			}
+			pendingOccurrences = append(pendingOccurrences, offset)
+		case "MD":
+			if token == "LD" {
+				rr.ByMonthDay = append(rr.ByMonthDay, -1)
+				continue
+			}
+			day, ok := parseVCalNumber(token)
+			if !ok {
+				ce.Add("invalid day number %q", token)
+				continue
+			}
+			rr.ByMonthDay = append(rr.ByMonthDay, int16(day))
+		case "YM":
+			month, err := strconv.Atoi(token)
+			if err != nil || month < 1 || month > 12 {
+				ce.Add("invalid month %q", token)
+				continue
+			}
+			rr.ByMonth = append(rr.ByMonth, int16(month))
+		case "YD":
+			day, ok := parseVCalNumber(token)
+			if !ok {
+				ce.Add("invalid year day %q", token)
+				continue
+			}
+			rr.ByYearDay = append(rr.ByYearDay, int16(day))
+		}
+	}
+
+	if len(times) > 0 {
+		// BYHOUR and BYMINUTE are a cross product, an arbitrary list of
+		// times can't always be represented.
+		if len(times) != len(hours)*len(minutes) {
+			ce.Add("times can't be expressed as BYHOUR and BYMINUTE")
+		}
+		rr.ByHour = hours
+		rr.ByMinute = minutes
+	}
+
+	if err := ce.Err(); err != nil {
+		return rr, err
+	}
+
+	return rr, rr.internal_parser()
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+func isVCalWeekday(s string) bool {
+	switch s {
+	case "SU", "MO", "TU", "WE", "TH", "FR", "SA":
+		return true
+	}
+	return false
+}
+
+func isVCalFrequency(s string) bool {
+	for _, prefix := range []string{"MP", "MD", "YM", "YD", "D", "W"} {
+		if strings.HasPrefix(s, prefix) && isDigits(s[len(prefix):]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVCalNumber parses numbers like "3", "3+" and "3-", the trailing
+// minus counting from the end of the period.
+func parseVCalNumber(s string) (int, bool) {
+	sign := 1
+	if strings.HasSuffix(s, "+") {
+		s = s[:len(s)-1]
+	} else if strings.HasSuffix(s, "-") {
+		sign = -1
+		s = s[:len(s)-1]
+	}
+
+	if !isDigits(s) {
+		return 0, false
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil || value == 0 {
+		return 0, false
+	}
+
+	return value * sign, true
+}
+
+func appendUniqueInt16(values []int16, value int16) []int16 {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func formatVCalNumber(value int) string {
+	if value < 0 {
+		return fmt.Sprintf("%d-", -value)
+	}
+	return fmt.Sprintf("%d+", value)
+}
+
+// VCalendarString converts the rule into vCalendar 1.0 recurrence syntax.
+// EXDATE is a separate property in vCalendar and is not included.
+func (rr *RecurringRule) VCalendarString() (string, error) {
+	ce := &ConversionError{Format: "vCalendar"}
+	var parts []string
+
+	if len(rr.BySecond) > 0 {
+		ce.Add("BYSECOND can not be represented")
+	}
+	if len(rr.ByWeekNo) > 0 {
+		ce.Add("BYWEEKNO can not be represented")
+	}
+	if len(rr.BySetPos) > 0 {
+		ce.Add("BYSETPOS can not be represented")
+	}
+	if rr.Frequency != DAILY && (len(rr.ByHour) > 0 || len(rr.ByMinute) > 0) {
+		ce.Add("BYHOUR and BYMINUTE are only supported with FREQ=DAILY")
+	}
+	if rr.Frequency != YEARLY && (len(rr.ByMonth) > 0 || len(rr.ByYearDay) > 0) {
+		ce.Add("BYMONTH and BYYEARDAY are only supported with FREQ=YEARLY")
+	}
+
+	interval := rr.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch rr.Frequency {
+	case DAILY:
+		parts = append(parts, fmt.Sprintf("D%d", interval))
+		if len(rr.ByDay) > 0 || len(rr.ByMonthDay) > 0 {
+			ce.Add("BYDAY and BYMONTHDAY are not supported with FREQ=DAILY")
+		}
+		if len(rr.ByHour) > 0 || len(rr.ByMinute) > 0 {
+			hours := rr.ByHour
+			minutes := rr.ByMinute
+			if len(hours) == 0 {
+				hours = []int16{int16(rr.DtStart.Hour())}
+			}
+			if len(minutes) == 0 {
+				minutes = []int16{int16(rr.DtStart.Minute())}
+			}
+			for _, h := range hours {
+				for _, m := range minutes {
+					parts = append(parts, fmt.Sprintf("%02d%02d", h, m))
+				}
+			}
+		}
+	case WEEKLY:
+		parts = append(parts, fmt.Sprintf("W%d", interval))
+		if len(rr.ByMonthDay) > 0 {
+			ce.Add("BYMONTHDAY is not supported with FREQ=WEEKLY")
+		}
+		for _, fd := range rr.ByDay {
+			if fd.Offset != 0 {
+				ce.Add("BYDAY offset %s is not supported with FREQ=WEEKLY", fd)
+			}
+			parts = append(parts, shortFromWeekday(fd.Weekday))
+		}
+	case MONTHLY:
+		if len(rr.ByDay) > 0 {
+			if len(rr.ByMonthDay) > 0 {
+				ce.Add("BYDAY and BYMONTHDAY can not be combined")
+			}
+			parts = append(parts, fmt.Sprintf("MP%d", interval))
+			for _, fd := range rr.ByDay {
+				if fd.Offset == 0 || fd.Offset < -5 || fd.Offset > 5 {
+					ce.Add("BYDAY %s needs an offset between -5 and 5", fd)
+				}
+				parts = append(parts, formatVCalNumber(fd.Offset), shortFromWeekday(fd.Weekday))
+			}
+		} else {
+			parts = append(parts, fmt.Sprintf("MD%d", interval))
+			for _, day := range rr.ByMonthDay {
+				if day < 0 {
+					parts = append(parts, formatVCalNumber(int(day)))
+				} else {
+					parts = append(parts, fmt.Sprintf("%d", day))
+				}
+			}
+		}
+	case YEARLY:
+		if len(rr.ByDay) > 0 || len(rr.ByMonthDay) > 0 {
+			ce.Add("BYDAY and BYMONTHDAY are not supported with FREQ=YEARLY")
+		}
+		if len(rr.ByYearDay) > 0 {
+			if len(rr.ByMonth) > 0 {
+				ce.Add("BYMONTH and BYYEARDAY can not be combined")
+			}
+			parts = append(parts, fmt.Sprintf("YD%d", interval))
+			for _, day := range rr.ByYearDay {
+				if day < 0 {
+					parts = append(parts, formatVCalNumber(int(day)))
+				} else {
+					parts = append(parts, fmt.Sprintf("%d", day))
+				}
+			}
+		} else {
+			parts = append(parts, fmt.Sprintf("YM%d", interval))
+			for _, month := range rr.ByMonth {
+				parts = append(parts, fmt.Sprintf("%d", month))
+			}
+		}
+	default:
+		ce.Add("FREQ=%s can not be represented", rr.Frequency)
+	}
+
+	if rr.Count > 0 {
+		parts = append(parts, fmt.Sprintf("#%d", rr.Count))
+	} else if !rr.Until.Equal(EmptyTime) {
+		parts = append(parts, DateTimeToString(rr.Until.In(time.UTC)))
+	} else {
+		parts = append(parts, "#0")
+	}
+
+	if err := ce.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.Join(parts, " "), nil
+}
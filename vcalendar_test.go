@@ -0,0 +1,103 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_VCalendar_Parse(t *testing.T) {
+	var start = time.Date(1997, time.September, 2, 9, 0, 0, 0, targetLocation)
+
+	var cases = map[string]string{
+		"W2 MO TU #10":        "RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=10;BYDAY=MO,TU",
+		"MD1 1 15 #0":         "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,15",
+		"MD1 LD 2- #3":        "RRULE:FREQ=MONTHLY;COUNT=3;BYMONTHDAY=-1,-2",
+		"MP1 1+ 1- FR #0":     "RRULE:FREQ=MONTHLY;BYDAY=1FR,-1FR",
+		"MP2 2+ MO TU 1- SU":  "RRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=2;BYDAY=2MO,2TU,-1SU",
+		"YM1 6 7 #10":         "RRULE:FREQ=YEARLY;COUNT=10;BYMONTH=6,7",
+		"YD3 1 100 200 #10":   "RRULE:FREQ=YEARLY;INTERVAL=3;COUNT=10;BYYEARDAY=1,100,200",
+		"D1 0800 1200 #5":     "RRULE:FREQ=DAILY;COUNT=5;BYHOUR=8,12;BYMINUTE=0",
+		"D2 19971224T000000Z": "RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=19971224T000000Z",
+	}
+
+	for value, expected := range cases {
+		rule, err := ParseVCalendar(value, start)
+		if err != nil {
+			t.Log("Failed to parse", value, err)
+			t.Fail()
+			continue
+		}
+
+		expectedRule, _ := Parse(expected)
+		expectedRule.DtStart = start
+
+		if !rule.Equal(expectedRule) {
+			t.Log("Rules don't match", value, rule.RecurString())
+			t.Fail()
+		}
+	}
+}
+
+func Test_VCalendar_RoundTrip(t *testing.T) {
+	var start = time.Date(1997, time.September, 2, 9, 0, 0, 0, targetLocation)
+
+	var values = []string{
+		"W2 MO TU #10",
+		"MD1 1 15 #0",
+		"MP1 1+ FR 1- FR #0",
+		"YM1 6 7 #10",
+		"YD3 1 100 200 #10",
+		"D1 0800 1200 #5",
+		"D2 19971224T000000Z",
+	}
+
+	for _, value := range values {
+		rule, err := ParseVCalendar(value, start)
+		if err != nil {
+			t.Log("Failed to parse", value, err)
+			t.Fail()
+			continue
+		}
+
+		result, err := rule.VCalendarString()
+		if err != nil || result != value {
+			t.Log("Failed to format", value, result, err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_VCalendar_Errors(t *testing.T) {
+	var start = time.Date(1997, time.September, 2, 9, 0, 0, 0, targetLocation)
+
+	var bad = []string{
+		"X1 #1",
+		"W1 XX #1",
+		"D1 0800 0930 #1",
+		"YM1 6 MP1 1+ MO #0",
+		"MD1 1$ #0",
+	}
+
+	for _, value := range bad {
+		if _, err := ParseVCalendar(value, start); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+
+	var unsupported = []string{
+		"RRULE:FREQ=HOURLY",
+		"RRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1",
+		"RRULE:FREQ=MONTHLY;BYDAY=1FR;BYMONTHDAY=13",
+		"RRULE:FREQ=WEEKLY;BYDAY=1FR",
+		"RRULE:FREQ=DAILY;BYSECOND=10",
+	}
+
+	for _, value := range unsupported {
+		rule, _ := Parse(value)
+		if _, err := rule.VCalendarString(); err == nil {
+			t.Log("Expected error for", value)
+			t.Fail()
+		}
+	}
+}